@@ -52,6 +52,13 @@ func (c *Cluster) Type() Type {
 // In this case its setting up indicies and weights
 func (c *Cluster) postProcess() bool {
 	element := c.Element()
+	if c.Scene().options.NoGeometry {
+		return true
+	}
+	// Clusters outside any skin have no vertices to weight
+	if c.Skin == nil {
+		return true
+	}
 	geom, ok := resolveObjectLinkReverse(c.Skin, GEOMETRY).(*Geometry)
 	if !ok {
 		return false
@@ -81,6 +88,9 @@ func (c *Cluster) postProcess() bool {
 	c.Indices = make([]int, 0, iLen)
 
 	for i := 0; i < iLen; i++ {
+		if oldIndices[i] < 0 || oldIndices[i] >= len(geom.newVerts) {
+			return false
+		}
 		n := &geom.newVerts[oldIndices[i]] //was a geometryimpl NewVertex
 		if n.index == -1 {
 			continue // skip vertices which aren't indexed.
@@ -0,0 +1,77 @@
+// Command fbxdump prints what an FBX file contains and can write out its thumbnail.
+//
+//	fbxdump [-thumbnail out.png] file.fbx
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/png"
+	"os"
+
+	"github.com/flywave/ofbx"
+)
+
+func main() {
+	thumbnail := flag.String("thumbnail", "", "write the scene's thumbnail to this PNG file")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: fbxdump [-thumbnail out.png] file.fbx")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *thumbnail); err != nil {
+		fmt.Fprintln(os.Stderr, "fbxdump:", err)
+		os.Exit(1)
+	}
+}
+
+func run(path, thumbnail string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	scene, err := ofbx.Load(f)
+	if err != nil {
+		return err
+	}
+
+	md := scene.Metadata
+	fmt.Printf("version: %d\n", md.Version)
+	fmt.Printf("creator: %s\n", md.Creator)
+	fmt.Printf("application: %s %s %s\n", md.Original.Vendor, md.Original.Name, md.Original.Version)
+	if md.LastSaved.Name != "" {
+		fmt.Printf("last saved by: %s %s %s\n", md.LastSaved.Vendor, md.LastSaved.Name, md.LastSaved.Version)
+	}
+	if !md.CreationTime.IsZero() {
+		fmt.Printf("created: %s\n", md.CreationTime.Format("2006-01-02 15:04:05"))
+	}
+	fmt.Printf("meshes: %d\n", len(scene.Meshes))
+	fmt.Printf("animation stacks: %d\n", len(scene.AnimationStacks))
+	fmt.Printf("videos: %d\n", len(scene.Videos))
+	fmt.Printf("thumbnail: %v\n", md.Thumbnail != nil)
+
+	if thumbnail == "" {
+		return nil
+	}
+	if md.Thumbnail == nil {
+		return fmt.Errorf("%s has no thumbnail", path)
+	}
+	img, err := md.Thumbnail.Image()
+	if err != nil {
+		return err
+	}
+	out, err := os.Create(thumbnail)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(out, img); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
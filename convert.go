@@ -0,0 +1,344 @@
+package ofbx
+
+import (
+	"strings"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/pkg/errors"
+)
+
+// Axis is one of the three canonical axes, numbered as they are stored in GlobalSettings
+type Axis int
+
+// Axis options
+const (
+	AxisX Axis = iota
+	AxisY Axis = iota
+	AxisZ Axis = iota
+)
+
+var axisNames = [3]string{"X", "Y", "Z"}
+
+// AxisSystem describes the orientation of a scene: which axis points up, which points
+// from the scene toward the viewer (front) and which completes the frame (coord).
+type AxisSystem struct {
+	Up, Front, Coord             Axis
+	UpSign, FrontSign, CoordSign int
+}
+
+// Common axis systems
+var (
+	// AxisSystemYUp is used by Maya, MotionBuilder, OpenGL and glTF
+	AxisSystemYUp = AxisSystem{Up: AxisY, Front: AxisZ, Coord: AxisX, UpSign: 1, FrontSign: 1, CoordSign: 1}
+	// AxisSystemZUp is used by 3ds Max and Blender
+	AxisSystemZUp = AxisSystem{Up: AxisZ, Front: AxisY, Coord: AxisX, UpSign: 1, FrontSign: -1, CoordSign: 1}
+	// AxisSystemYUpLeftHanded is used by DirectX and Unity
+	AxisSystemYUpLeftHanded = AxisSystem{Up: AxisY, Front: AxisZ, Coord: AxisX, UpSign: 1, FrontSign: -1, CoordSign: 1}
+)
+
+// AxisSystem returns the axis system described by the settings, falling back
+// to AxisSystemYUp when the file does not define a usable one
+func (s *Settings) AxisSystem() AxisSystem {
+	a := AxisSystem{
+		Up:        Axis(s.UpAxis),
+		Front:     Axis(s.FrontAxis),
+		Coord:     Axis(s.CoordAxis),
+		UpSign:    s.UpAxisSign,
+		FrontSign: s.FrontAxisSign,
+		CoordSign: s.CoordAxisSign,
+	}
+	if !a.valid() {
+		return AxisSystemYUp
+	}
+	a.UpSign = signOf(a.UpSign)
+	a.FrontSign = signOf(a.FrontSign)
+	a.CoordSign = signOf(a.CoordSign)
+	return a
+}
+
+func (a AxisSystem) valid() bool {
+	for _, ax := range []Axis{a.Up, a.Front, a.Coord} {
+		if ax < AxisX || ax > AxisZ {
+			return false
+		}
+	}
+	return a.Up != a.Front && a.Up != a.Coord && a.Front != a.Coord
+}
+
+// RightHanded reports whether coord x up points along front
+func (a AxisSystem) RightHanded() bool {
+	return newAxisConversion(AxisSystemYUp, a).det() > 0
+}
+
+func (a AxisSystem) basis() [3]signedAxis {
+	return [3]signedAxis{
+		{int(a.Coord), float64(signOf(a.CoordSign))},
+		{int(a.Up), float64(signOf(a.UpSign))},
+		{int(a.Front), float64(signOf(a.FrontSign))},
+	}
+}
+
+func signOf(i int) int {
+	if i < 0 {
+		return -1
+	}
+	return 1
+}
+
+type signedAxis struct {
+	axis int
+	sign float64
+}
+
+// conversion is a change of basis made of an axis permutation, per axis signs and a
+// uniform scale: component a of a position moves to component perm[a], multiplied by sign[a]*scale
+type conversion struct {
+	perm  [3]int
+	sign  [3]float64
+	scale float64
+}
+
+func newAxisConversion(from, to AxisSystem) conversion {
+	c := conversion{scale: 1}
+	src, dst := from.basis(), to.basis()
+	for i := range src {
+		c.perm[src[i].axis] = dst[i].axis
+		c.sign[src[i].axis] = src[i].sign * dst[i].sign
+	}
+	return c
+}
+
+func newScaleConversion(scale float64) conversion {
+	return conversion{perm: [3]int{0, 1, 2}, sign: [3]float64{1, 1, 1}, scale: scale}
+}
+
+func (c conversion) isIdentity() bool {
+	return c.perm == [3]int{0, 1, 2} && c.sign == [3]float64{1, 1, 1} && c.scale == 1
+}
+
+// det returns the sign of the conversion's determinant; negative conversions mirror the scene
+func (c conversion) det() float64 {
+	d := c.sign[0] * c.sign[1] * c.sign[2]
+	// odd permutations of three elements are exactly those with a fixed point
+	for a, p := range c.perm {
+		if a == p && c.perm != [3]int{0, 1, 2} {
+			return -d
+		}
+	}
+	return d
+}
+
+func (c conversion) apply(v floatgeom.Point3, factor func(a int) float64) floatgeom.Point3 {
+	var out floatgeom.Point3
+	for a := 0; a < 3; a++ {
+		out[c.perm[a]] = v[a] * factor(a)
+	}
+	return out
+}
+
+func (c conversion) position(v floatgeom.Point3) floatgeom.Point3 {
+	return c.apply(v, func(a int) float64 { return c.sign[a] * c.scale })
+}
+
+func (c conversion) direction(v floatgeom.Point3) floatgeom.Point3 {
+	return c.apply(v, func(a int) float64 { return c.sign[a] })
+}
+
+// rotation converts euler angles; a rotation about axis a becomes a rotation about
+// the converted axis, reversed when the conversion mirrors the scene
+func (c conversion) rotation(v floatgeom.Point3) floatgeom.Point3 {
+	d := c.det()
+	return c.apply(v, func(a int) float64 { return c.sign[a] * d })
+}
+
+func (c conversion) scaling(v floatgeom.Point3) floatgeom.Point3 {
+	return c.apply(v, func(int) float64 { return 1 })
+}
+
+func (c conversion) rotationOrder(o RotationOrder) RotationOrder {
+	axes := o.axes()
+	for i, a := range axes {
+		axes[i] = c.perm[a]
+	}
+	return rotationOrderFromAxes(axes)
+}
+
+func (c conversion) matrix() Matrix {
+	m := Matrix{}
+	for a := 0; a < 3; a++ {
+		m.m[a*4+c.perm[a]] = c.sign[a] * c.scale
+	}
+	m.m[15] = 1
+	return m
+}
+
+func (c conversion) inverse() Matrix {
+	m := Matrix{}
+	for a := 0; a < 3; a++ {
+		m.m[c.perm[a]*4+a] = c.sign[a] / c.scale
+	}
+	m.m[15] = 1
+	return m
+}
+
+// conjugate re-expresses a transform between two spaces that were both converted
+func (c conversion) conjugate(m Matrix) Matrix {
+	return c.matrix().Mul(m).Mul(c.inverse())
+}
+
+// ConvertAxisSystem rewrites the scene from the axis system in its Settings to the target one.
+// Node transforms and pivots, geometry, cluster matrices and animation curves are all converted;
+// when the handedness changes, the scene is mirrored and polygon winding is reversed.
+func (s *Scene) ConvertAxisSystem(target AxisSystem) error {
+	if !target.valid() {
+		return errors.New("Invalid target axis system")
+	}
+	s.applyConversion(newAxisConversion(s.Settings.AxisSystem(), target))
+	s.Settings.UpAxis = UpVector(target.Up)
+	s.Settings.UpAxisSign = signOf(target.UpSign)
+	s.Settings.FrontAxis = FrontVector(target.Front)
+	s.Settings.FrontAxisSign = signOf(target.FrontSign)
+	s.Settings.CoordAxis = CoordSystem(target.Coord)
+	s.Settings.CoordAxisSign = signOf(target.CoordSign)
+	return nil
+}
+
+// MetersPerUnit returns the length of one scene unit in meters.
+// FBX stores this as UnitScaleFactor, in centimeters.
+func (s *Settings) MetersPerUnit() float64 {
+	if s.UnitScaleFactor <= 0 {
+		return 0.01
+	}
+	return float64(s.UnitScaleFactor) / 100
+}
+
+// ConvertUnits rescales every length in the scene so that one unit equals metersPerUnit meters
+func (s *Scene) ConvertUnits(metersPerUnit float64) error {
+	if metersPerUnit <= 0 {
+		return errors.New("Invalid unit scale")
+	}
+	s.applyConversion(newScaleConversion(s.Settings.MetersPerUnit() / metersPerUnit))
+	s.Settings.UnitScaleFactor = float32(metersPerUnit * 100)
+	return nil
+}
+
+func (s *Scene) applyConversion(c conversion) {
+	if c.isIdentity() {
+		return
+	}
+	var clusters []*Cluster
+	visited := make(map[*AnimationCurve]bool)
+	for _, obj := range s.ObjectMap {
+		if obj == nil {
+			continue
+		}
+		switch o := obj.(type) {
+		case *Geometry:
+			c.convertGeometry(o)
+		case *Cluster:
+			o.Transform = c.conjugate(o.Transform)
+			o.TransformLink = c.conjugate(o.TransformLink)
+			clusters = append(clusters, o)
+		case *AnimationCurveNode:
+			c.convertCurveNode(o, visited)
+		default:
+			if obj.IsNode() && obj.Type() != ROOT {
+				c.convertNode(obj)
+			}
+		}
+	}
+	if c.det() < 0 {
+		// cluster indices refer to triangulated vertices, which moved with the winding
+		for _, cluster := range clusters {
+			if cluster.Skin != nil {
+				cluster.postProcess()
+			}
+		}
+	}
+}
+
+func (c conversion) convertNode(o Obj) {
+	for _, name := range []string{"Lcl Translation", "RotationOffset", "RotationPivot", "ScalingOffset", "ScalingPivot", "GeometricTranslation"} {
+		setVec3Property(o, name, c.position(resolveVec3Property(o, name, floatgeom.Point3{})))
+	}
+	for _, name := range []string{"Lcl Scaling", "GeometricScaling"} {
+		setVec3Property(o, name, c.scaling(resolveVec3Property(o, name, floatgeom.Point3{1, 1, 1})))
+	}
+	for _, name := range []string{"Lcl Rotation", "PreRotation", "PostRotation"} {
+		setVec3Property(o, name, c.rotation(resolveVec3Property(o, name, floatgeom.Point3{})))
+	}
+	order := getRotationOrder(o)
+	if converted := c.rotationOrder(order); converted != order {
+		setEnumProperty(o, "RotationOrder", int(converted))
+	}
+	// Geometric rotation is always evaluated as EulerXYZ, so it can't just be permuted
+	if rot := resolveVec3Property(o, "GeometricRotation", floatgeom.Point3{}); rot != (floatgeom.Point3{}) {
+		unscaled := c
+		unscaled.scale = 1
+		setVec3Property(o, "GeometricRotation", eulerXYZFromMatrix(unscaled.conjugate(EulerXYZ.rotationMatrix(rot))))
+	}
+}
+
+func (c conversion) convertGeometry(g *Geometry) {
+	for i, v := range g.Vertices {
+		g.Vertices[i] = c.position(v)
+	}
+	for _, data := range g.vectorData() {
+		for i, v := range data {
+			data[i] = c.direction(v)
+		}
+	}
+	if c.det() < 0 {
+		// mirroring flips the handedness of the tangent basis
+		for i := range g.BitangentSigns {
+			g.BitangentSigns[i] = -g.BitangentSigns[i]
+		}
+		g.reverseWinding()
+	}
+}
+
+func (c conversion) convertCurveNode(acn *AnimationCurveNode, visited map[*AnimationCurve]bool) {
+	var convert func(floatgeom.Point3) floatgeom.Point3
+	switch acn.BoneLinkProp {
+	case BoneTranslate:
+		convert = c.position
+	case BoneRotate:
+		convert = c.rotation
+	case BoneScale:
+		convert = c.scaling
+	default:
+		return
+	}
+
+	var curves [3]Curve
+	for a := 0; a < 3; a++ {
+		var unit floatgeom.Point3
+		unit[a] = 1
+		k := c.perm[a]
+		factor := float32(convert(unit)[k])
+		curve := acn.Curves[a]
+		if curve.Curve != nil && !visited[curve.Curve] {
+			visited[curve.Curve] = true
+			for i := range curve.Curve.Values {
+				curve.Curve.Values[i] *= factor
+			}
+		}
+		if curve.connection != nil && strings.HasPrefix(curve.connection.property, "d|") {
+			curve.connection.property = "d|" + axisNames[k]
+		}
+		curves[k] = curve
+	}
+	acn.Curves = curves
+
+	var defaults floatgeom.Point3
+	for a := 0; a < 3; a++ {
+		if resolveProperty(acn, "d|"+axisNames[a]) == nil {
+			return
+		}
+		defaults[a] = resolveDoubleProperty(acn, "d|"+axisNames[a], 0)
+	}
+	defaults = convert(defaults)
+	for a := 0; a < 3; a++ {
+		setDoubleProperty(acn, "d|"+axisNames[a], defaults[a])
+	}
+}
@@ -0,0 +1,170 @@
+package ofbx
+
+import (
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func worldVertices(s *Scene) [][]floatgeom.Point3 {
+	out := [][]floatgeom.Point3{}
+	for _, m := range s.Meshes {
+		mtx := m.GetGlobalMatrix()
+		vs := make([]floatgeom.Point3, len(m.Geometry.Vertices))
+		for i, v := range m.Geometry.Vertices {
+			vs[i] = mtx.MulPosition(v)
+		}
+		out = append(out, vs)
+	}
+	return out
+}
+
+func assertMatrixInDelta(t *testing.T, expected, actual Matrix) {
+	for i := range expected.m {
+		assert.InDelta(t, expected.m[i], actual.m[i], 1e-6, "%v != %v", expected, actual)
+	}
+}
+
+func TestSettingsAxisSystem(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	assert.Equal(t, AxisSystemYUp, scene.Settings.AxisSystem())
+	assert.True(t, AxisSystemYUp.RightHanded())
+	assert.True(t, AxisSystemZUp.RightHanded())
+	assert.False(t, AxisSystemYUpLeftHanded.RightHanded())
+
+	// Missing settings fall back to Y up
+	assert.Equal(t, AxisSystemYUp, (&Settings{}).AxisSystem())
+}
+
+func TestAxisConversion(t *testing.T) {
+	c := newAxisConversion(AxisSystemZUp, AxisSystemYUp)
+	assert.Equal(t, floatgeom.Point3{1, 3, -2}, c.position(floatgeom.Point3{1, 2, 3}))
+	assert.Equal(t, 1.0, c.det())
+	assertMatrixInDelta(t, makeIdentity(), c.matrix().Mul(c.inverse()))
+
+	mirror := newAxisConversion(AxisSystemYUp, AxisSystemYUpLeftHanded)
+	assert.Equal(t, floatgeom.Point3{1, 2, -3}, mirror.position(floatgeom.Point3{1, 2, 3}))
+	assert.Equal(t, -1.0, mirror.det())
+
+	for _, c := range []conversion{c, mirror, newAxisConversion(AxisSystemYUpLeftHanded, AxisSystemZUp)} {
+		for _, order := range []RotationOrder{EulerXYZ, EulerXZY, EulerYZX, EulerYXZ, EulerZXY, EulerZYX} {
+			rot := floatgeom.Point3{10, -35, 70}
+			expected := c.conjugate(order.rotationMatrix(rot))
+			actual := c.rotationOrder(order).rotationMatrix(c.rotation(rot))
+			assertMatrixInDelta(t, expected, actual)
+		}
+	}
+}
+
+func TestSceneConvertAxisSystem(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	before := worldVertices(scene)
+	c := newAxisConversion(AxisSystemYUp, AxisSystemZUp)
+
+	require.NoError(t, scene.ConvertAxisSystem(AxisSystemZUp))
+	assert.Equal(t, AxisSystemZUp, scene.Settings.AxisSystem())
+	after := worldVertices(scene)
+	for i := range before {
+		for j := range before[i] {
+			assertPointsInDelta(t, c.position(before[i][j]), after[i][j])
+		}
+	}
+}
+
+func TestSceneConvertAxisSystemMirror(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	before := worldVertices(scene)
+	geom := scene.Meshes[0].Geometry
+	face := append([]int{}, geom.Faces[0]...)
+	normals := append([]floatgeom.Point3{}, geom.Normals...)
+	c := newAxisConversion(AxisSystemYUp, AxisSystemYUpLeftHanded)
+
+	require.NoError(t, scene.ConvertAxisSystem(AxisSystemYUpLeftHanded))
+	after := worldVertices(scene)
+	for i := range before {
+		for j := range before[i] {
+			assertPointsInDelta(t, c.position(before[i][j]), after[i][j])
+		}
+	}
+	assert.Equal(t, []int{face[0], face[2], face[1]}, geom.Faces[0])
+	assertPointsInDelta(t, c.direction(normals[2]), geom.Normals[1])
+	assert.Len(t, geom.GetOldVerts(), 36)
+}
+
+func TestSceneConvertAxisSystemInvalid(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	assert.Error(t, scene.ConvertAxisSystem(AxisSystem{Up: AxisY, Front: AxisY, Coord: AxisX}))
+}
+
+func TestSceneConvertUnits(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	assert.InDelta(t, 0.01, scene.Settings.MetersPerUnit(), 1e-9)
+	before := worldVertices(scene)
+
+	require.NoError(t, scene.ConvertUnits(1))
+	assert.Equal(t, float32(100), scene.Settings.UnitScaleFactor)
+	after := worldVertices(scene)
+	for i := range before {
+		for j := range before[i] {
+			assertPointsInDelta(t, before[i][j].MulConst(0.01), after[i][j])
+		}
+	}
+	assert.Error(t, scene.ConvertUnits(0))
+}
+
+func TestConvertNodeTransform(t *testing.T) {
+	scene := &Scene{ObjectMap: map[uint64]Obj{}}
+	node := NewNode(scene, &Element{ID: NewDataView("Model")}, NULL_NODE)
+	addProperty70(node, "Lcl Translation", "Lcl Translation", "", "A", newDoubleProperty(1), newDoubleProperty(2), newDoubleProperty(3))
+	addProperty70(node, "Lcl Rotation", "Lcl Rotation", "", "A", newDoubleProperty(30), newDoubleProperty(-45), newDoubleProperty(60))
+	addProperty70(node, "Lcl Scaling", "Lcl Scaling", "", "A", newDoubleProperty(1), newDoubleProperty(2), newDoubleProperty(3))
+	addProperty70(node, "RotationPivot", "Vector3D", "Vector", "", newDoubleProperty(4), newDoubleProperty(5), newDoubleProperty(6))
+	addProperty70(node, "PreRotation", "Vector3D", "Vector", "", newDoubleProperty(-90), newDoubleProperty(0), newDoubleProperty(15))
+
+	for _, c := range []conversion{
+		newAxisConversion(AxisSystemZUp, AxisSystemYUp),
+		newAxisConversion(AxisSystemYUp, AxisSystemYUpLeftHanded),
+		newScaleConversion(0.1),
+	} {
+		expected := c.conjugate(GetLocalTransform(node))
+		c.convertNode(node)
+		assertMatrixInDelta(t, expected, GetLocalTransform(node))
+	}
+}
+
+func TestConvertCurveNode(t *testing.T) {
+	scene := &Scene{ObjectMap: map[uint64]Obj{}}
+	acn := NewAnimationCurveNode(scene, &Element{ID: NewDataView("AnimationCurveNode")})
+	acn.BoneLinkProp = BoneTranslate
+	for a := 0; a < 3; a++ {
+		addProperty70(acn, "d|"+axisNames[a], "Number", "", "A", newDoubleProperty(float64(a+1)))
+		acn.Curves[a].Curve = &AnimationCurve{Values: []float32{float32(a + 1)}}
+		acn.Curves[a].connection = &Connection{property: "d|" + axisNames[a]}
+	}
+
+	newAxisConversion(AxisSystemZUp, AxisSystemYUp).convertCurveNode(acn, map[*AnimationCurve]bool{})
+	assert.Equal(t, []float32{1}, acn.Curves[0].Curve.Values)
+	assert.Equal(t, []float32{3}, acn.Curves[1].Curve.Values)
+	assert.Equal(t, []float32{-2}, acn.Curves[2].Curve.Values)
+	assert.Equal(t, "d|Z", acn.Curves[2].connection.property)
+	assert.Equal(t, -2.0, resolveDoubleProperty(acn, "d|Z", 0))
+}
+
+func TestConvertGeometricTransform(t *testing.T) {
+	scene := &Scene{ObjectMap: map[uint64]Obj{}}
+	mesh := NewMesh(scene, &Element{ID: NewDataView("Model")})
+	addProperty70(mesh, "GeometricTranslation", "Vector3D", "Vector", "", newDoubleProperty(1), newDoubleProperty(-2), newDoubleProperty(3))
+	addProperty70(mesh, "GeometricRotation", "Vector3D", "Vector", "", newDoubleProperty(20), newDoubleProperty(40), newDoubleProperty(-75))
+	addProperty70(mesh, "GeometricScaling", "Vector3D", "Vector", "", newDoubleProperty(2), newDoubleProperty(1), newDoubleProperty(0.5))
+
+	for _, c := range []conversion{
+		newAxisConversion(AxisSystemYUp, AxisSystemZUp),
+		newAxisConversion(AxisSystemZUp, AxisSystemYUpLeftHanded),
+	} {
+		expected := c.conjugate(mesh.getGeometricMatrix())
+		c.convertNode(mesh)
+		assertMatrixInDelta(t, expected, mesh.getGeometricMatrix())
+	}
+}
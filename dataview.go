@@ -3,7 +3,6 @@ package ofbx
 import (
 	"bytes"
 	"encoding/binary"
-	"fmt"
 	"io"
 )
 
@@ -26,86 +25,69 @@ func BufferDataView(buff *bytes.Buffer) *DataView {
 	}
 }
 
+// String returns all of the data. It doesn't move the reader, so it is safe to call concurrently.
 func (dv *DataView) String() string {
-	dv.Seek(0, io.SeekStart)
-	ln := dv.Len()
-	data := make([]byte, ln)
-	_, err := dv.Read(data)
-	if err != nil && err != io.EOF {
-		fmt.Println(err)
-	}
-	// Todo: maybe don't do this?
-	dv.Seek(0, io.SeekStart)
+	data := make([]byte, dv.Size())
+	// Reading all of a bytes.Reader from the start can't fail
+	dv.ReadAt(data, 0)
 	return string(data)
 }
 
+// read decodes the start of the data into v, failing with io.ErrUnexpectedEOF when there isn't
+// enough of it. Like String it doesn't move the reader.
+func (dv *DataView) read(v interface{}) error {
+	err := binary.Read(io.NewSectionReader(dv, 0, dv.Size()), binary.LittleEndian, v)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// touint64 returns the data as a uint64, or 0 when there isn't enough of it
 func (dv *DataView) touint64() uint64 {
 	var i uint64
-	dv.Seek(0, io.SeekStart)
-
-	err := binary.Read(dv, binary.LittleEndian, &i)
-	if err != nil && err != io.EOF {
-		fmt.Println("binary read failure:", err)
-	}
+	dv.read(&i)
 	return i
 }
 
+// toint64 returns the data as an int64, or 0 when there isn't enough of it
 func (dv *DataView) toint64() int64 {
 	var i int64
-	dv.Seek(0, io.SeekStart)
-	err := binary.Read(dv, binary.LittleEndian, &i)
-	if err != nil && err != io.EOF {
-		fmt.Println("binary read failure:", err)
-	}
+	dv.read(&i)
 	return i
 }
 
+// toInt32 returns the data as an int32, or 0 when there isn't enough of it
 func (dv *DataView) toInt32() int32 {
 	var i int32
-	dv.Seek(0, io.SeekStart)
-	err := binary.Read(dv, binary.LittleEndian, &i)
-	if err != nil && err != io.EOF {
-		fmt.Println("binary read failure:", err)
-	}
+	dv.read(&i)
 	return i
 }
 
+// toUint32 returns the data as a uint32, or 0 when there isn't enough of it
 func (dv *DataView) toUint32() uint32 {
 	var i uint32
-	dv.Seek(0, io.SeekStart)
-	err := binary.Read(dv, binary.LittleEndian, &i)
-	if err != nil && err != io.EOF {
-		fmt.Println("binary read failure:", err)
-	}
+	dv.read(&i)
 	return i
 }
 
+// toDouble returns the data as a float64, or 0 when there isn't enough of it
 func (dv *DataView) toDouble() float64 {
 	var i float64
-	dv.Seek(0, io.SeekStart)
-	err := binary.Read(dv, binary.LittleEndian, &i)
-	if err != nil && err != io.EOF {
-		fmt.Println("binary read failure:", err)
-	}
+	dv.read(&i)
 	return i
 }
 
+// toFloat returns the data as a float32, or 0 when there isn't enough of it
 func (dv *DataView) toFloat() float32 {
 	var i float32
-	dv.Seek(0, io.SeekStart)
-	err := binary.Read(dv, binary.LittleEndian, &i)
-	if err != nil && err != io.EOF {
-		fmt.Println("binary read failure:", err)
-	}
+	dv.read(&i)
 	return i
 }
 
+// toBool returns the data as a bool, or false when there isn't any
 func (dv *DataView) toBool() bool {
 	var i bool
-	dv.Seek(0, io.SeekStart)
-	err := binary.Read(dv, binary.LittleEndian, &i)
-	if err != nil && err != io.EOF {
-		fmt.Println("binary read failure:", err)
-	}
+	dv.read(&i)
 	return i
 }
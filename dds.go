@@ -0,0 +1,235 @@
+package ofbx
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ddsMagic      = "DDS "
+	ddsHeaderSize = 128
+	ddsDX10Size   = 20
+)
+
+// DDS pixel format flags
+const (
+	ddpfAlphaPixels = 0x1
+	ddpfAlpha       = 0x2
+	ddpfFourCC      = 0x4
+	ddpfRGB         = 0x40
+	ddpfLuminance   = 0x20000
+)
+
+// DXGI formats of the DX10 header that can be decoded
+const (
+	dxgiR8G8B8A8     = 28
+	dxgiR8G8B8A8SRGB = 29
+	dxgiBC1          = 71
+	dxgiBC1SRGB      = 72
+	dxgiBC2          = 74
+	dxgiBC2SRGB      = 75
+	dxgiBC3          = 77
+	dxgiBC3SRGB      = 78
+	dxgiB8G8R8A8     = 87
+	dxgiB8G8R8A8SRGB = 91
+)
+
+// ddsMasks describes an uncompressed pixel layout by the bits of each channel
+type ddsMasks struct {
+	bitCount   int
+	r, g, b, a uint32
+	luminance  bool
+}
+
+// decodeDDS decodes the top mip level of an uncompressed or BC1 to BC3 compressed DDS
+func decodeDDS(data []byte) (image.Image, error) {
+	if len(data) < ddsHeaderSize || string(data[:4]) != ddsMagic {
+		return nil, errors.New("Invalid DDS: bad header")
+	}
+	le := binary.LittleEndian
+	height := int(le.Uint32(data[12:]))
+	width := int(le.Uint32(data[16:]))
+	pfFlags := le.Uint32(data[80:])
+	fourCC := string(data[84:88])
+	if width <= 0 || height <= 0 || width > 1<<16 || height > 1<<16 {
+		return nil, errors.Errorf("Invalid DDS: bad size %dx%d", width, height)
+	}
+	pixels := data[ddsHeaderSize:]
+
+	if pfFlags&ddpfFourCC != 0 {
+		if fourCC == "DX10" {
+			if len(pixels) < ddsDX10Size {
+				return nil, errors.New("Invalid DDS: truncated DX10 header")
+			}
+			format := le.Uint32(pixels)
+			pixels = pixels[ddsDX10Size:]
+			switch format {
+			case dxgiBC1, dxgiBC1SRGB:
+				fourCC = "DXT1"
+			case dxgiBC2, dxgiBC2SRGB:
+				fourCC = "DXT3"
+			case dxgiBC3, dxgiBC3SRGB:
+				fourCC = "DXT5"
+			case dxgiR8G8B8A8, dxgiR8G8B8A8SRGB:
+				return decodeDDSMasks(pixels, width, height, ddsMasks{bitCount: 32, r: 0xff, g: 0xff00, b: 0xff0000, a: 0xff000000})
+			case dxgiB8G8R8A8, dxgiB8G8R8A8SRGB:
+				return decodeDDSMasks(pixels, width, height, ddsMasks{bitCount: 32, r: 0xff0000, g: 0xff00, b: 0xff, a: 0xff000000})
+			default:
+				return nil, errors.Errorf("Invalid DDS: unsupported DXGI format %d", format)
+			}
+		}
+		switch fourCC {
+		case "DXT1", "DXT2", "DXT3", "DXT4", "DXT5":
+			return decodeBC(pixels, width, height, fourCC)
+		}
+		return nil, errors.Errorf("Invalid DDS: unsupported format %q", fourCC)
+	}
+
+	masks := ddsMasks{
+		bitCount:  int(le.Uint32(data[88:])),
+		r:         le.Uint32(data[92:]),
+		g:         le.Uint32(data[96:]),
+		b:         le.Uint32(data[100:]),
+		a:         le.Uint32(data[104:]),
+		luminance: pfFlags&ddpfLuminance != 0,
+	}
+	if pfFlags&(ddpfAlphaPixels|ddpfAlpha) == 0 {
+		masks.a = 0
+	}
+	if pfFlags&(ddpfRGB|ddpfLuminance|ddpfAlpha) == 0 {
+		return nil, errors.New("Invalid DDS: unsupported pixel format")
+	}
+	return decodeDDSMasks(pixels, width, height, masks)
+}
+
+func decodeDDSMasks(data []byte, width, height int, masks ddsMasks) (image.Image, error) {
+	size := masks.bitCount / 8
+	if size < 1 || size > 4 || masks.bitCount%8 != 0 {
+		return nil, errors.Errorf("Invalid DDS: unsupported bit count %d", masks.bitCount)
+	}
+	if len(data) < width*height*size {
+		return nil, errors.New("Invalid DDS: truncated pixel data")
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	var px [4]byte
+	for i := 0; i < width*height; i++ {
+		copy(px[:], data[i*size:(i+1)*size])
+		v := binary.LittleEndian.Uint32(px[:])
+		c := color.NRGBA{
+			R: maskedChannel(v, masks.r, 0),
+			G: maskedChannel(v, masks.g, 0),
+			B: maskedChannel(v, masks.b, 0),
+			A: maskedChannel(v, masks.a, 255),
+		}
+		if masks.luminance {
+			c.G, c.B = c.R, c.R
+		}
+		img.SetNRGBA(i%width, i/width, c)
+	}
+	return img, nil
+}
+
+// maskedChannel extracts the bits of mask from v and scales them to 8 bits
+func maskedChannel(v, mask uint32, missing uint8) uint8 {
+	if mask == 0 {
+		return missing
+	}
+	shift := bits.TrailingZeros32(mask)
+	max := uint64(mask >> uint(shift))
+	return uint8(uint64((v&mask)>>uint(shift)) * 255 / max)
+}
+
+// decodeBC decodes BC1 (DXT1), BC2 (DXT2/3) and BC3 (DXT4/5) block compressed data
+func decodeBC(data []byte, width, height int, fourCC string) (image.Image, error) {
+	blockSize := 16
+	if fourCC == "DXT1" {
+		blockSize = 8
+	}
+	blocksX, blocksY := (width+3)/4, (height+3)/4
+	if len(data) < blocksX*blocksY*blockSize {
+		return nil, errors.New("Invalid DDS: truncated block data")
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	var block [16]color.NRGBA
+	for by := 0; by < blocksY; by++ {
+		for bx := 0; bx < blocksX; bx++ {
+			b := data[(by*blocksX+bx)*blockSize:]
+			switch fourCC {
+			case "DXT1":
+				decodeBCColors(b, &block, true)
+			case "DXT2", "DXT3":
+				decodeBCColors(b[8:], &block, false)
+				for i := range block {
+					a := b[i/2] >> (4 * uint(i%2)) & 0xf
+					block[i].A = a * 17
+				}
+			default:
+				decodeBCColors(b[8:], &block, false)
+				decodeBCAlpha(b, &block)
+			}
+			for i, c := range block {
+				x, y := bx*4+i%4, by*4+i/4
+				if x < width && y < height {
+					img.SetNRGBA(x, y, c)
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+// decodeBCColors decodes the 8 byte color part of a block. Only BC1 has the 3 color mode with transparent black.
+func decodeBCColors(b []byte, block *[16]color.NRGBA, bc1 bool) {
+	c0, c1 := binary.LittleEndian.Uint16(b), binary.LittleEndian.Uint16(b[2:])
+	var palette [4]color.NRGBA
+	palette[0], palette[1] = rgb565(c0), rgb565(c1)
+	mix := func(w0, w1, d int) color.NRGBA {
+		return color.NRGBA{
+			R: uint8((w0*int(palette[0].R) + w1*int(palette[1].R)) / d),
+			G: uint8((w0*int(palette[0].G) + w1*int(palette[1].G)) / d),
+			B: uint8((w0*int(palette[0].B) + w1*int(palette[1].B)) / d),
+			A: 255,
+		}
+	}
+	if c0 > c1 || !bc1 {
+		palette[2], palette[3] = mix(2, 1, 3), mix(1, 2, 3)
+	} else {
+		palette[2], palette[3] = mix(1, 1, 2), color.NRGBA{}
+	}
+	indices := binary.LittleEndian.Uint32(b[4:])
+	for i := range block {
+		block[i] = palette[indices>>(2*uint(i))&3]
+	}
+}
+
+// decodeBCAlpha decodes the 8 byte interpolated alpha part of a BC3 block
+func decodeBCAlpha(b []byte, block *[16]color.NRGBA) {
+	var alphas [8]int
+	alphas[0], alphas[1] = int(b[0]), int(b[1])
+	if alphas[0] > alphas[1] {
+		for i := 1; i < 7; i++ {
+			alphas[i+1] = ((7-i)*alphas[0] + i*alphas[1]) / 7
+		}
+	} else {
+		for i := 1; i < 5; i++ {
+			alphas[i+1] = ((5-i)*alphas[0] + i*alphas[1]) / 5
+		}
+		alphas[6], alphas[7] = 0, 255
+	}
+	var indices uint64
+	for i := 0; i < 6; i++ {
+		indices |= uint64(b[2+i]) << (8 * uint(i))
+	}
+	for i := range block {
+		block[i].A = uint8(alphas[indices>>(3*uint(i))&7])
+	}
+}
+
+func rgb565(v uint16) color.NRGBA {
+	r, g, b := v>>11&0x1f, v>>5&0x3f, v&0x1f
+	return color.NRGBA{R: uint8(r<<3 | r>>2), G: uint8(g<<2 | g>>4), B: uint8(b<<3 | b>>2), A: 255}
+}
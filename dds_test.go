@@ -0,0 +1,102 @@
+package ofbx
+
+import (
+	"encoding/binary"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ddsHeader(width, height int, pfFlags uint32, fourCC string, bitCount uint32, masks ...uint32) []byte {
+	h := make([]byte, ddsHeaderSize)
+	copy(h, ddsMagic)
+	le := binary.LittleEndian
+	le.PutUint32(h[4:], 124)
+	le.PutUint32(h[12:], uint32(height))
+	le.PutUint32(h[16:], uint32(width))
+	le.PutUint32(h[76:], 32)
+	le.PutUint32(h[80:], pfFlags)
+	copy(h[84:88], fourCC)
+	le.PutUint32(h[88:], bitCount)
+	for i, m := range masks {
+		le.PutUint32(h[92+4*i:], m)
+	}
+	return h
+}
+
+func TestDecodeDDSUncompressed(t *testing.T) {
+	data := append(ddsHeader(2, 1, ddpfRGB|ddpfAlphaPixels, "", 32, 0xff0000, 0xff00, 0xff, 0xff000000),
+		1, 2, 3, 4, 5, 6, 7, 8,
+	)
+	img, err := decodeImage("a.dds", data)
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{3, 2, 1, 4}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{7, 6, 5, 8}, img.At(1, 0))
+
+	// 16 bit 565 without alpha
+	data = append(ddsHeader(1, 1, ddpfRGB, "", 16, 0xf800, 0x07e0, 0x001f), 0x00, 0xf8)
+	img, err = decodeDDS(data)
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{255, 0, 0, 255}, img.At(0, 0))
+
+	_, err = decodeDDS(data[:len(data)-1])
+	assert.Error(t, err)
+}
+
+func bc1Block(c0, c1 uint16, indices uint32) []byte {
+	b := make([]byte, 8)
+	binary.LittleEndian.PutUint16(b, c0)
+	binary.LittleEndian.PutUint16(b[2:], c1)
+	binary.LittleEndian.PutUint32(b[4:], indices)
+	return b
+}
+
+func TestDecodeDDSBC1(t *testing.T) {
+	// Red and blue endpoints; the first row uses every palette entry
+	block := bc1Block(0xf800, 0x001f, 0|1<<2|2<<4|3<<6)
+	img, err := decodeDDS(append(ddsHeader(4, 4, ddpfFourCC, "DXT1", 0), block...))
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{255, 0, 0, 255}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{0, 0, 255, 255}, img.At(1, 0))
+	assert.Equal(t, color.NRGBA{170, 0, 85, 255}, img.At(2, 0))
+	assert.Equal(t, color.NRGBA{85, 0, 170, 255}, img.At(3, 0))
+
+	// Endpoints in the other order switch to 3 colors and transparent black
+	block = bc1Block(0x001f, 0xf800, 2|3<<2)
+	img, err = decodeDDS(append(ddsHeader(2, 2, ddpfFourCC, "DXT1", 0), block...))
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{127, 0, 127, 255}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{}, img.At(1, 0))
+
+	// The DX10 header names the same format
+	dx10 := make([]byte, ddsDX10Size)
+	binary.LittleEndian.PutUint32(dx10, dxgiBC1)
+	img, err = decodeDDS(append(append(ddsHeader(2, 2, ddpfFourCC, "DX10", 0), dx10...), block...))
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{}, img.At(1, 0))
+
+	_, err = decodeDDS(ddsHeader(4, 4, ddpfFourCC, "DXT1", 0))
+	assert.Error(t, err)
+	_, err = decodeDDS(append(ddsHeader(4, 4, ddpfFourCC, "ATI2", 0), make([]byte, 16)...))
+	assert.Error(t, err)
+}
+
+func TestDecodeDDSBC2AndBC3(t *testing.T) {
+	colors := bc1Block(0xffff, 0x0000, 0)
+
+	bc2 := append([]byte{0x0f, 0, 0, 0, 0, 0, 0, 0}, colors...)
+	img, err := decodeDDS(append(ddsHeader(4, 4, ddpfFourCC, "DXT3", 0), bc2...))
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{255, 255, 255, 255}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{255, 255, 255, 0}, img.At(1, 0))
+
+	// Alpha endpoints 255 and 0, indices 0, 1 and 2 on the first pixels
+	bc3 := append([]byte{255, 0, 0 | 1<<3 | 2<<6, 0, 0, 0, 0, 0}, colors...)
+	img, err = decodeDDS(append(ddsHeader(4, 4, ddpfFourCC, "DXT5", 0), bc3...))
+	require.NoError(t, err)
+	assert.Equal(t, uint8(255), img.At(0, 0).(color.NRGBA).A)
+	assert.Equal(t, uint8(0), img.At(1, 0).(color.NRGBA).A)
+	assert.Equal(t, uint8(218), img.At(2, 0).(color.NRGBA).A)
+}
@@ -7,6 +7,9 @@ type Element struct {
 	ID         *DataView
 	Children   []*Element
 	Properties []*Property
+
+	// offset is where the element starts in the file, 0 for elements made in memory
+	offset int64
 }
 
 func (e *Element) getProperty(idx int) *Property {
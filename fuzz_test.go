@@ -0,0 +1,160 @@
+package ofbx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// textSeed is a small text FBX file, as testdata only has binary ones
+const textSeed = `; FBX 7.4.0 project file
+FBXHeaderExtension:  {
+	FBXHeaderVersion: 1003
+	Creator: "ofbx"
+}
+Objects:  {
+	Geometry: 1, "Geometry::cube", "Mesh" {
+		Vertices: *9 {
+			a: 0,0,0,1,0,0,0,1.5,-2e3
+		}
+		PolygonVertexIndex: *3 {
+			a: 0,1,-3
+		}
+	}
+}
+Connections:  {
+	C: "OO",1,0
+}
+`
+
+// addSeeds adds the files in testdata to the corpus of f
+func addSeeds(f *testing.F) {
+	var paths []string
+	for _, pattern := range []string{"testdata/*.fbx", "testdata/*.FBX"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			f.Fatal(err)
+		}
+		paths = append(paths, matches...)
+	}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte(textSeed))
+}
+
+func FuzzTokenize(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		tokenize(context.Background(), bytes.NewReader(data), LoadOptions{})
+	})
+}
+
+func FuzzTokenizeText(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		countReader := NewCountReader(bytes.NewReader(data))
+		cursor := &Cursor{Reader: bufio.NewReader(countReader), cr: countReader}
+		for {
+			if err := cursor.skipWhitespaces(); err != nil {
+				return
+			}
+			if _, err := cursor.Peek(1); err == io.EOF {
+				return
+			}
+			if _, err := cursor.ReadTextElement(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+func FuzzLoad(f *testing.F) {
+	addSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, opts := range []LoadOptions{{}, {Lenient: true}} {
+			scene, err := LoadWithOptions(bytes.NewReader(data), opts)
+			if err == nil && scene == nil {
+				t.Fatal("no scene and no error")
+			}
+		}
+	})
+}
+
+// TestMalformedInput covers what fuzzing found panicking
+func TestMalformedInput(t *testing.T) {
+	vs, err := parseBinaryArrayVec2(newDoubleArrayProperty(1, 2, 3))
+	require.NoError(t, err)
+	assert.Len(t, vs, 1)
+	vs4, err := parseBinaryArrayVec4(newDoubleArrayProperty(1, 2, 3, 4, 5))
+	require.NoError(t, err)
+	assert.Len(t, vs4, 1)
+
+	// Only the header
+	_, err = Load(bytes.NewReader([]byte("Kaydara FBX Binary  \x00\x1a\x00\xe8\x1c\x00\x00")))
+	assert.NoError(t, err)
+
+	// An element claiming more properties than there could be memory for
+	_, _, err = tokenize(context.Background(), bytes.NewReader(append([]byte("Kaydara FBX Binary  \x00\x1a\x00\xe8\x1c\x00\x00"),
+		0xff, 0, 0, 0, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 1, 'A')), LoadOptions{})
+	assert.Error(t, err)
+
+	root := newTestRoot([]*Element{
+		newObjectElement("Geometry", 2, "Mesh",
+			newTestElement("Vertices", []*Property{newDoubleArrayProperty(0, 0, 0, 1, 0, 0, 0, 1, 0)}),
+			newTestElement("PolygonVertexIndex", []*Property{newIntArrayProperty(0, 1, -4)}),
+		),
+	})
+	_, err = newScene(context.Background(), root, Header{Version: 7400}, LoadOptions{})
+	assert.Error(t, err)
+
+	root = newTestRoot([]*Element{
+		newTriangleGeometry(2),
+		newObjectElement("Deformer", 6, "Cluster",
+			newTestElement("Indexes", []*Property{newIntArrayProperty(9)}),
+			newTestElement("Weights", []*Property{newDoubleArrayProperty(1)}),
+		),
+		newObjectElement("Deformer", 7, "Skin"),
+	},
+		newConnection("OO", 6, 7),
+		newConnection("OO", 7, 2),
+	)
+	scene, err := newScene(context.Background(), root, Header{Version: 7400}, LoadOptions{Lenient: true})
+	require.NoError(t, err)
+	require.Len(t, scene.Warnings, 1)
+	assert.Equal(t, WarningPostProcess, scene.Warnings[0].Code)
+	assert.Equal(t, uint64(6), scene.Warnings[0].ObjectID)
+
+	// IDs too short for their type are reported rather than read as zero
+	var id uint64
+	assert.ErrorIs(t, NewDataView("12").read(&id), io.ErrUnexpectedEOF)
+	assert.Zero(t, NewDataView("12").touint64())
+	root = newTestRoot([]*Element{newTestElement("Model", []*Property{{Type: LONG, value: NewDataView("12")}})},
+		newTestElement("C", []*Property{newStringProperty("OO"), {Type: LONG, value: NewDataView("1")}, newLongProperty(0)}),
+	)
+	scene, err = newScene(context.Background(), root, Header{Version: 7400}, LoadOptions{Lenient: true})
+	require.NoError(t, err)
+	require.Len(t, scene.Warnings, 2)
+	assert.Equal(t, WarningInvalidConnection, scene.Warnings[0].Code)
+	assert.Equal(t, WarningInvalidObject, scene.Warnings[1].Code)
+
+	// Values out of range fall back to defaults
+	euler := floatgeom.Point3{10, 20, 30}
+	assert.Equal(t, EulerXYZ.rotationMatrix(euler), RotationOrder(99).rotationMatrix(euler))
+	assert.Nil(t, splatVec3(VertexDataMapping(9), []floatgeom.Point3{{1, 2, 3}}, nil, []int{-1}))
+	var v int
+	assert.Equal(t, "", IntFromString("x", "longer", &v))
+	assert.Zero(t, v)
+}
@@ -15,6 +15,7 @@ const (
 	ByPolygonVertex VertexDataMapping = iota
 	ByPolygon       VertexDataMapping = iota
 	ByVertex        VertexDataMapping = iota
+	AllSame         VertexDataMapping = iota
 )
 
 var vtxDataMapFromStrs = map[string]VertexDataMapping{
@@ -22,23 +23,53 @@ var vtxDataMapFromStrs = map[string]VertexDataMapping{
 	"ByPolygon":       ByPolygon,
 	"ByVertex":        ByVertex,
 	"ByVertice":       ByVertex,
+	"AllSame":         AllSame,
 }
 
 // MaxUvs is the highest number of UVs allowed
 const MaxUvs = 4
 
 // Geometry is the base geometric shape objec that is implemented in forms such as meshes that dictate control point deformations
+// Vertices holds the control points and Faces the polygons indexing them. Normals, Tangents, UVs and Colors
+// hold one value per polygon vertex, in the order the corners of Faces are listed.
 type Geometry struct {
 	Object
 	Skin *Skin
 
-	Vertices, Normals, Tangents []floatgeom.Point3
-
-	UVs                 [MaxUvs][]floatgeom.Point2
-	Colors              []floatgeom.Point4
-	Materials, oldVerts []int
-	newVerts            []Vertex
-	Faces               [][]int
+	Vertices, Normals, Tangents, Binormals []floatgeom.Point3
+
+	UVs    [MaxUvs][]floatgeom.Point2
+	Colors []floatgeom.Point4
+	// BitangentSigns holds the handedness of the tangent basis of each polygon vertex when it is known,
+	// the bitangent being BitangentSigns[i] * cross(Normals[i], Tangents[i])
+	BitangentSigns []float64
+	// Materials holds the material slot of every triangle, in the order BuildTriangleMesh emits them
+	// with ear clipping. It is empty without a material layer or when polygons aren't triangulated.
+	Materials []int
+	oldVerts  []int
+	newVerts  []Vertex
+	Faces     [][]int
+	// UVSets, ColorSets, NormalSets, TangentSets and BinormalSets hold every layer of each kind in the file.
+	// UVs, Colors, Normals, Tangents and Binormals share their data with the first sets.
+	UVSets                                []UVSet
+	ColorSets                             []ColorSet
+	NormalSets, TangentSets, BinormalSets []VectorSet
+	// MaterialSets holds the material slot of every polygon for each material layer
+	MaterialSets []MaterialSet
+	// Layers tells which sets are meant to be used together
+	Layers []Layer
+	// Holes flags the polygons that are cut out of the closest preceding polygon that isn't a hole
+	Holes []bool
+	// Edges holds the control points at both ends of every edge, in the order per edge layers use
+	Edges [][2]int
+	// SmoothingGroups holds a bit mask per polygon; polygons sharing a bit are smoothed together
+	SmoothingGroups []int
+	// HardEdges flags the edges that normals are not smoothed across
+	HardEdges []bool
+	// EdgeCreases and VertexCreases hold the subdivision crease weight of every edge and control point
+	EdgeCreases, VertexCreases []float64
+
+	polygonMaterials []int
 }
 
 func (g *Geometry) String() string {
@@ -180,6 +211,14 @@ func (nv *Vertex) add(index int) {
 	}
 }
 
+func newVertexLinks(count int) []Vertex {
+	links := make([]Vertex, count)
+	for i := range links {
+		links[i].index = -1
+	}
+	return links
+}
+
 // NewGeometry makes a stub Geometry
 func NewGeometry(scene *Scene, element *Element) *Geometry {
 	g := &Geometry{}
@@ -193,34 +232,6 @@ func (g *Geometry) Type() Type {
 	return GEOMETRY
 }
 
-func (g *Geometry) triangulate(indices []int) []int {
-	old := make([]int, 0)
-	polyIdx := 0
-	for i := 0; i < len(indices); i++ {
-		idx := indices[i]
-		if idx < 0 {
-			idx = (-idx) - 1
-		}
-
-		if polyIdx <= 2 {
-			g.oldVerts = append(g.oldVerts, idx)
-			old = append(old, i)
-		} else {
-			g.oldVerts = append(g.oldVerts, indices[i-polyIdx])
-			old = append(old, i-polyIdx)
-			g.oldVerts = append(g.oldVerts, indices[i-1])
-			old = append(old, i-1)
-			g.oldVerts = append(g.oldVerts, idx)
-			old = append(old, i)
-		}
-		polyIdx++
-		if indices[i] < 0 {
-			polyIdx = 0
-		}
-	}
-	return old
-}
-
 func parseGeometry(scene *Scene, element *Element) (*Geometry, error) {
 	if element.Properties == nil {
 		return nil, errors.New("Geometry invalid")
@@ -246,10 +257,20 @@ func parseGeometry(scene *Scene, element *Element) (*Geometry, error) {
 		return nil, err
 	}
 
+	var limits Limits
+	if scene != nil {
+		limits = scene.options.Limits
+	}
 	geom.Faces = make([][]int, 0)
 	curFace := []int{}
 	//Parse out the polygons. List of vertex references with a negative value indicating the last vertex of a face.
 	for _, v := range origIndices {
+		if v >= len(vertices) || -v > len(vertices) {
+			return nil, fmt.Errorf("Polygon vertex index %d out of range of %d vertices", v, len(vertices))
+		}
+		if err := limits.checkPolygonCorners(len(curFace) + 1); err != nil {
+			return nil, err
+		}
 		if v < 0 {
 			curFace = append(curFace, (v*-1)-1)
 			geom.Faces = append(geom.Faces, curFace)
@@ -259,132 +280,273 @@ func parseGeometry(scene *Scene, element *Element) (*Geometry, error) {
 		}
 	}
 
-	toOldIndices := geom.triangulate(origIndices)
-	geom.Vertices = make([]floatgeom.Point3, len(geom.oldVerts))
+	geom.Vertices = vertices
 
-	for i, vIdx := range geom.oldVerts {
-		v := vertices[vIdx]
-		geom.Vertices[i] = v
+	for _, elem := range element.Children {
+		if elem.ID.String() != "LayerElementMaterial" {
+			continue
+		}
+		set, err := parseMaterialSet(elem, geom.Faces)
+		if err != nil {
+			if err := geom.skipLayer(elem, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if set != nil {
+			geom.MaterialSets = append(geom.MaterialSets, *set)
+		}
 	}
-
-	geom.newVerts = make([]Vertex, len(geom.Vertices))
-
-	for i := 0; i < len(geom.oldVerts); i++ {
-		old := geom.oldVerts[i]
-		geom.newVerts[old].add(i)
+	if len(geom.MaterialSets) > 0 {
+		geom.polygonMaterials = geom.MaterialSets[0].Materials
 	}
 
-	layerMaterialElements := findChildren(element, "LayerElementMaterial")
-	if len(layerMaterialElements) > 0 {
-		mappingProp := findChildProperty(layerMaterialElements[0], "MappingInformationType")
-		referenceProp := findChildProperty(layerMaterialElements[0], "ReferenceInformationType")
-		if len(mappingProp) == 0 || len(referenceProp) == 0 {
-			return nil, errors.New("invalid LayerElementMaterial")
+	if edgesProp := findSingleChildProperty(element, "Edges"); edgesProp != nil {
+		tmp, err := parseBinaryArrayInt(edgesProp)
+		if err != nil {
+			return nil, err
 		}
+		geom.Edges = edgesFromPolygonVertices(tmp, origIndices)
+	}
 
-		if mappingProp[0].value.String() == "ByPolygon" &&
-			referenceProp[0].value.String() == "IndexToDirect" {
-			geom.Materials = make([]int, len(geom.Vertices)/3)
-			for i := 0; i < len(geom.Vertices)/3; i++ {
-				geom.Materials[i] = -1
+	layerSmoothingElems := findChildren(element, "LayerElementSmoothing")
+	if len(layerSmoothingElems) > 0 {
+		mappingProp := findSingleChildProperty(layerSmoothingElems[0], "MappingInformationType")
+		smoothingProp := findSingleChildProperty(layerSmoothingElems[0], "Smoothing")
+		if mappingProp == nil || smoothingProp == nil {
+			if err := geom.skipLayer(layerSmoothingElems[0], errors.New("invalid LayerElementSmoothing")); err != nil {
+				return nil, err
 			}
-
-			indiciesProp := findChildProperty(layerMaterialElements[0], "Materials")
-			if indiciesProp == nil {
-				return nil, errors.New("invalid LayerElementMaterial")
+		} else if tmp, err := parseBinaryArrayInt(smoothingProp); err != nil {
+			if err := geom.skipLayer(layerSmoothingElems[0], err); err != nil {
+				return nil, err
 			}
+		} else {
+			switch mappingProp.value.String() {
+			case "ByPolygon":
+				geom.SmoothingGroups = tmp
+			case "ByEdge":
+				geom.HardEdges = make([]bool, len(tmp))
+				for i, smooth := range tmp {
+					geom.HardEdges[i] = smooth == 0
+				}
+			}
+		}
+	}
 
-			tmp, err := parseBinaryArrayInt(indiciesProp[0])
+	layerEdgeCreaseElems := findChildren(element, "LayerElementEdgeCrease")
+	if len(layerEdgeCreaseElems) > 0 {
+		if creases := findSingleChildProperty(layerEdgeCreaseElems[0], "EdgeCrease"); creases != nil {
+			geom.EdgeCreases, err = parseBinaryArrayFloat64(creases)
 			if err != nil {
-				return nil, err
+				if err := geom.skipLayer(layerEdgeCreaseElems[0], err); err != nil {
+					return nil, err
+				}
+				geom.EdgeCreases = nil
 			}
+		}
+	}
 
-			tmpI := 0
-			triCt := 0
-			insertIdx := 0
-			for poly := 0; poly < len(tmp); poly++ {
-				triCt, tmpI = getTriCountFromPoly(origIndices, tmpI)
-				for i := 0; i < triCt; i++ {
-					geom.Materials[insertIdx] = tmp[poly]
-					insertIdx++
+	layerVertexCreaseElems := findChildren(element, "LayerElementVertexCrease")
+	if len(layerVertexCreaseElems) > 0 {
+		if creases := findSingleChildProperty(layerVertexCreaseElems[0], "VertexCrease"); creases != nil {
+			geom.VertexCreases, err = parseBinaryArrayFloat64(creases)
+			if err != nil {
+				if err := geom.skipLayer(layerVertexCreaseElems[0], err); err != nil {
+					return nil, err
 				}
-			}
-		} else {
-			if mappingProp[0].value.String() != "AllSame" {
-				return nil, errors.New("mapping not supported")
+				geom.VertexCreases = nil
 			}
 		}
 	}
 
-	for _, elem := range element.Children {
-		if elem.ID.String() != "LayerElementUV" {
-			continue
-		}
-		uvIdx := 0
-		if len(elem.Properties) > 0 {
-			uvIdx = int(elem.Properties[0].value.toInt32())
-		}
-		if uvIdx >= 0 && uvIdx < MaxUvs {
-			tmp, tmpIndices, mapping, err := parseVertexDataVec2(elem, "UV", "UVIndex")
+	layerHoleElems := findChildren(element, "LayerElementHole")
+	if len(layerHoleElems) > 0 {
+		if holes := findSingleChildProperty(layerHoleElems[0], "Hole"); holes != nil {
+			geom.Holes, err = parseBinaryArrayBool(holes)
 			if err != nil {
-				return nil, err
-			}
-			if len(tmp) > 0 {
-				//uvs = [4]floatgeom.Point2{} //resize(tmpIndices.empty() ? tmp.size() : tmpIndices.size());
-				geom.UVs[uvIdx] = splatVec2(mapping, tmp, tmpIndices, origIndices)
+				if err := geom.skipLayer(layerHoleElems[0], err); err != nil {
+					return nil, err
+				}
+				geom.Holes = nil
 			}
 		}
+	}
 
+	if err := geom.parseLayerElements(element, origIndices); err != nil {
+		return nil, err
 	}
+	geom.Layers = parseLayers(element)
+
+	// Holes are cut out when triangulating, so this comes once they are known
+	geom.linkVertices(len(vertices))
+
+	return geom, nil
+}
 
-	layerTangentElems := findChildren(element, "LayerElementTangents")
-	if len(layerTangentElems) > 0 {
-		tans := findChildren(layerTangentElems[0], "Tangents")
-		var tmp []floatgeom.Point3
-		var tmpIndices []int
-		var mapping VertexDataMapping
+// parseMaterialSet reads the material slot of every polygon from a material layer element. The values are
+// always slots in the model's materials, whether the reference is Direct or IndexToDirect. Mappings that
+// don't make sense for materials use the value of the polygon's first corner, and unknown mappings are
+// skipped by returning nil.
+func parseMaterialSet(elem *Element, faces [][]int) (*MaterialSet, error) {
+	mapping := "AllSame"
+	if mappingProp := findSingleChildProperty(elem, "MappingInformationType"); mappingProp != nil {
+		mapping = mappingProp.value.String()
+	}
+	var values []int
+	if materialsProp := findSingleChildProperty(elem, "Materials"); materialsProp != nil {
 		var err error
-		if len(tans) > 0 {
-			tmp, tmpIndices, mapping, err = parseVertexDataVec3(layerTangentElems[0], "Tangents", "TangentsIndex")
-		} else {
-			tmp, tmpIndices, mapping, err = parseVertexDataVec3(layerTangentElems[0], "Tangent", "TangentIndex")
-		}
-		if err != nil {
+		if values, err = parseBinaryArrayInt(materialsProp); err != nil {
 			return nil, err
 		}
-		if len(tmp) > 0 {
-			geom.Tangents = splatVec3(mapping, tmp, tmpIndices, origIndices)
-			RemapVec3(&geom.Tangents, toOldIndices)
+	}
+	valueAt := func(i int) int {
+		if i < 0 || i >= len(values) {
+			return -1
 		}
+		return values[i]
 	}
 
-	layerColorElems := findChildren(element, "LayerElementColor")
-	if len(layerColorElems) > 0 {
-		tmp, tmpIndices, mapping, err := parseVertexDataVec4(layerColorElems[0], "Colors", "ColorIndex")
-		if err != nil {
-			return nil, err
-		}
-		if len(tmp) > 0 {
-			geom.Colors = splatVec4(mapping, tmp, tmpIndices, origIndices)
+	set := &MaterialSet{
+		Name:      layerElementName(elem),
+		Index:     layerElementIndex(elem),
+		Materials: make([]int, len(faces)),
+	}
+	pv := 0
+	for poly, face := range faces {
+		switch mapping {
+		case "AllSame", "NoMappingInformation":
+			set.Materials[poly] = 0
+			if len(values) > 0 {
+				set.Materials[poly] = values[0]
+			}
+		case "ByPolygon":
+			set.Materials[poly] = valueAt(poly)
+		case "ByPolygonVertex":
+			set.Materials[poly] = valueAt(pv)
+		case "ByVertex", "ByVertice", "ByControlPoint":
+			set.Materials[poly] = -1
+			if len(face) > 0 {
+				set.Materials[poly] = valueAt(face[0])
+			}
+		default:
+			return nil, nil
 		}
+		pv += len(face)
 	}
+	return set, nil
+}
 
-	layerNormalElems := findChildren(element, "LayerElementNormal")
-	if len(layerNormalElems) > 0 {
-		tmp, tmpIndices, mapping, err := parseVertexDataVec3(layerNormalElems[0], "Normals", "NormalsIndex")
-		if err != nil {
-			return nil, err
-		}
-		if len(tmp) > 0 {
-			geom.Normals = splatVec3(mapping, tmp, tmpIndices, origIndices)
+// parseLayerElements reads every UV, color, normal, tangent and binormal set. The first set of each kind,
+// and the UV sets within MaxUvs, also fill the matching Geometry field.
+func (g *Geometry) parseLayerElements(element *Element, origIndices []int) error {
+	for _, elem := range element.Children {
+		name, index := layerElementName(elem), layerElementIndex(elem)
+		switch elem.ID.String() {
+		case "LayerElementUV":
+			tmp, tmpIndices, mapping, err := parseVertexDataVec2(elem, "UV", "UVIndex")
+			if err != nil {
+				if err := g.skipLayer(elem, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(tmp) == 0 {
+				continue
+			}
+			uvs := expandVec2(mapping, tmp, tmpIndices, origIndices)
+			g.UVSets = append(g.UVSets, UVSet{Name: name, Index: index, UVs: uvs})
+			if index >= 0 && index < MaxUvs {
+				g.UVs[index] = uvs
+			}
+		case "LayerElementColor":
+			tmp, tmpIndices, mapping, err := parseVertexDataVec4(elem, "Colors", "ColorIndex")
+			if err != nil {
+				if err := g.skipLayer(elem, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(tmp) == 0 {
+				continue
+			}
+			colors := expandVec4(mapping, tmp, tmpIndices, origIndices)
+			g.ColorSets = append(g.ColorSets, ColorSet{Name: name, Index: index, Colors: colors})
+			if len(g.ColorSets) == 1 {
+				g.Colors = colors
+			}
+		case "LayerElementNormal":
+			set, err := parseVectorSet(elem, "Normals", origIndices)
+			if err != nil {
+				if err := g.skipLayer(elem, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if set != nil {
+				g.NormalSets = append(g.NormalSets, *set)
+				if len(g.NormalSets) == 1 {
+					g.Normals = set.Vectors
+				}
+			}
+		case "LayerElementTangent", "LayerElementTangents":
+			set, err := parseVectorSet(elem, "Tangents", origIndices)
+			if err != nil {
+				if err := g.skipLayer(elem, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if set != nil {
+				g.TangentSets = append(g.TangentSets, *set)
+				if len(g.TangentSets) == 1 {
+					g.Tangents = set.Vectors
+				}
+			}
+		case "LayerElementBinormal":
+			set, err := parseVectorSet(elem, "Binormals", origIndices)
+			if err != nil {
+				if err := g.skipLayer(elem, err); err != nil {
+					return err
+				}
+				continue
+			}
+			if set != nil {
+				g.BinormalSets = append(g.BinormalSets, *set)
+				if len(g.BinormalSets) == 1 {
+					g.Binormals = set.Vectors
+				}
+			}
 		}
 	}
+	g.BitangentSigns = bitangentSigns(g.Normals, g.Tangents, g.Binormals)
+	return nil
+}
 
-	// Todo: undo / redo some work above to not require redoing vertices
-
-	geom.Vertices = vertices
+// skipLayer returns the error of a layer element that failed to parse. When loading leniently it
+// records a warning instead and returns nil, so that the layer is left out.
+func (g *Geometry) skipLayer(elem *Element, err error) error {
+	return g.scene.tolerate(WarningInvalidLayer, elementObjectID(g.element), elementError(elem, err))
+}
 
-	return geom, nil
+// parseVectorSet reads the vectors of a normal, tangent or binormal layer element, which some exporters
+// name in the singular, or returns nil when it holds none
+func parseVectorSet(elem *Element, name string, origIndices []int) (*VectorSet, error) {
+	if len(findChildProperty(elem, name)) == 0 {
+		name = name[:len(name)-1]
+	}
+	tmp, tmpIndices, mapping, err := parseVertexDataVec3(elem, name, name+"Index")
+	if err != nil {
+		return nil, err
+	}
+	if len(tmp) == 0 {
+		return nil, nil
+	}
+	return &VectorSet{
+		Name:    layerElementName(elem),
+		Index:   layerElementIndex(elem),
+		Vectors: expandVec3(mapping, tmp, tmpIndices, origIndices),
+	}, nil
 }
 
 func (g *Geometry) applyMatrix(m *Matrix) {
@@ -392,7 +554,81 @@ func (g *Geometry) applyMatrix(m *Matrix) {
 		g.Vertices[i] = m.MulPosition(g.Vertices[i])
 	}
 
-	for i := 0; i < len(g.Normals); i++ {
-		g.Normals[i] = m.MulDirection(g.Normals[i])
+	for _, data := range g.vectorData() {
+		for i := 0; i < len(data); i++ {
+			data[i] = m.MulDirection(data[i])
+		}
+	}
+}
+
+// reverseWinding flips the orientation of every polygon while keeping its first vertex in place.
+// Per polygon-vertex attributes are reordered to follow their vertices.
+func (g *Geometry) reverseWinding() {
+	remap := make([]int, 0)
+	for _, face := range g.Faces {
+		start := len(remap)
+		remap = append(remap, start)
+		for i := len(face) - 1; i > 0; i-- {
+			remap = append(remap, start+i)
+		}
+		for i, j := 1, len(face)-1; i < j; i, j = i+1, j-1 {
+			face[i], face[j] = face[j], face[i]
+		}
+	}
+	// Reorder in place so the sets keep sharing their data with the matching fields
+	for _, data := range g.vectorData() {
+		copy(data, reorderVec3(data, remap))
+	}
+	for _, data := range g.uvData() {
+		copy(data, reorderVec2(data, remap))
+	}
+	for _, data := range g.colorData() {
+		copy(data, reorderVec4(data, remap))
+	}
+	g.BitangentSigns = reorderFloat64(g.BitangentSigns, remap)
+
+	g.linkVertices(len(g.Vertices))
+}
+
+// polygonsKept reports whether the scene was loaded without triangulation
+func (g *Geometry) polygonsKept() bool {
+	return g.scene != nil && g.scene.options.NoTriangulate
+}
+
+// linkVertices triangulates the faces, fills Materials and links every control point to the
+// triangulated vertices made from it. The triangles are the ones BuildTriangleMesh makes by
+// default, so cluster indices and materials line up with them. Without triangulation every
+// control point links to itself.
+func (g *Geometry) linkVertices(count int) {
+	g.oldVerts = make([]int, 0)
+	g.newVerts = newVertexLinks(count)
+	if g.polygonsKept() {
+		for i := range g.newVerts {
+			g.newVerts[i].add(i)
+		}
+		return
+	}
+	corners := g.cornerControlPoints()
+	tris := g.triangulateFaces(TriangulateEarClipping)
+	g.Materials = nil
+	if len(g.polygonMaterials) != 0 {
+		g.Materials = make([]int, 0, len(tris))
+	}
+	for _, tri := range tris {
+		for _, pv := range tri.corners {
+			g.oldVerts = append(g.oldVerts, corners[pv])
+		}
+		if g.Materials != nil {
+			material := -1
+			if tri.polygon < len(g.polygonMaterials) {
+				material = g.polygonMaterials[tri.polygon]
+			}
+			g.Materials = append(g.Materials, material)
+		}
+	}
+	for i, old := range g.oldVerts {
+		if old < count {
+			g.newVerts[old].add(i)
+		}
 	}
 }
@@ -1,6 +1,7 @@
 package ofbx
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/oakmound/oak/v2/alg/floatgeom"
@@ -91,29 +92,44 @@ func TestGeometryTriangulate(t *testing.T) {
 	element := &Element{ID: NewDataView("test_geom")}
 	geom := NewGeometry(scene, element)
 
-	// Test quad (4 vertices) - should produce 3 triangles (fan triangulation)
-	indices := []int{0, 1, 2, 3, -1}
-	old := geom.triangulate(indices)
-
-	// Should have 9 indices (3 triangles * 3 vertices for quad)
-	if len(old) != 9 {
-		t.Errorf("Expected 9 indices for quad triangulation, got %d", len(old))
+	// Concave quad: the fan from corner 0 would go outside it around the notch at corner 1
+	geom.Vertices = []floatgeom.Point3{{0, 0, 0}, {2, 1, 0}, {4, 0, 0}, {2, 3, 0}}
+	geom.Faces = [][]int{{0, 1, 2, 3}, {3, 2, 1}}
+	geom.polygonMaterials = []int{4, 7}
+	geom.linkVertices(len(geom.Vertices))
+
+	var want []int
+	var materials []int
+	for _, tri := range geom.triangulateFaces(TriangulateEarClipping) {
+		for _, pv := range tri.corners {
+			want = append(want, geom.cornerControlPoints()[pv])
+		}
+		materials = append(materials, geom.polygonMaterials[tri.polygon])
+	}
+	if !reflect.DeepEqual(want, geom.GetOldVerts()) {
+		t.Errorf("oldVerts = %v, want the ear clipped triangles %v", geom.GetOldVerts(), want)
+	}
+	if !reflect.DeepEqual(materials, geom.Materials) || !reflect.DeepEqual([]int{4, 4, 7}, geom.Materials) {
+		t.Errorf("Materials = %v, want %v", geom.Materials, materials)
+	}
+	if reflect.DeepEqual([]int{0, 1, 2, 0, 2, 3}, geom.GetOldVerts()[:6]) {
+		t.Error("Concave quad was fan triangulated")
 	}
 
-	// Test triangle (3 vertices) - should produce 2 triangles
-	indices = []int{0, 1, 2, -1}
-	old = geom.triangulate(indices)
-
-	// Should have 6 indices (2 triangles * 3 vertices)
-	if len(old) != 6 {
-		t.Errorf("Expected 6 indices for triangle triangulation, got %d", len(old))
+	// A hole is cut out rather than triangulated
+	geom.Holes = []bool{false, true}
+	geom.linkVertices(len(geom.Vertices))
+	for _, material := range geom.Materials {
+		if material != 4 {
+			t.Errorf("Materials with a hole = %v, want only the outer polygon's", geom.Materials)
+			break
+		}
 	}
 
-	// Test empty indices
-	indices = []int{}
-	old = geom.triangulate(indices)
-	if len(old) != 0 {
-		t.Errorf("Expected 0 indices for empty input, got %d", len(old))
+	geom.Faces = nil
+	geom.linkVertices(len(geom.Vertices))
+	if len(geom.GetOldVerts()) != 0 {
+		t.Errorf("Expected no vertices without faces, got %v", geom.GetOldVerts())
 	}
 }
 
@@ -287,10 +303,76 @@ func TestGeometryGetOldVerts(t *testing.T) {
 	}
 
 	// After triangulation, should have data
-	indices := []int{0, 1, 2, -1}
-	geom.triangulate(indices)
+	geom.Vertices = []floatgeom.Point3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	geom.Faces = [][]int{{0, 1, 2}}
+	geom.linkVertices(len(geom.Vertices))
 	oldVerts = geom.GetOldVerts()
 	if len(oldVerts) == 0 {
 		t.Error("Expected oldVerts to have data after triangulation")
 	}
 }
+
+func newMaterialLayerElement(mapping, reference string, materials ...int32) *Element {
+	children := []*Element{
+		newTestElement("MappingInformationType", []*Property{newStringProperty(mapping)}),
+		newTestElement("ReferenceInformationType", []*Property{newStringProperty(reference)}),
+	}
+	if materials != nil {
+		children = append(children, newTestElement("Materials", []*Property{newIntArrayProperty(materials...)}))
+	}
+	return newTestElement("LayerElementMaterial", []*Property{newIntegerProperty(0)}, children...)
+}
+
+func TestParseMaterialSet(t *testing.T) {
+	faces := [][]int{{0, 1, 2, 3}, {1, 4, 2}}
+	tests := []struct {
+		name     string
+		elem     *Element
+		expected []int
+	}{
+		{"ByPolygon", newMaterialLayerElement("ByPolygon", "IndexToDirect", 1, 2), []int{1, 2}},
+		{"ByPolygonDirect", newMaterialLayerElement("ByPolygon", "Direct", 3), []int{3, -1}},
+		{"AllSame", newMaterialLayerElement("AllSame", "IndexToDirect", 2), []int{2, 2}},
+		{"AllSameEmpty", newMaterialLayerElement("AllSame", "IndexToDirect"), []int{0, 0}},
+		{"ByPolygonVertex", newMaterialLayerElement("ByPolygonVertex", "Direct", 0, 0, 0, 0, 1, 1, 1), []int{0, 1}},
+		{"ByVertex", newMaterialLayerElement("ByVertice", "Direct", 4, 5, 6, 7, 8), []int{4, 5}},
+	}
+	for _, tc := range tests {
+		set, err := parseMaterialSet(tc.elem, faces)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.name, err)
+		}
+		if set == nil || !reflect.DeepEqual(set.Materials, tc.expected) {
+			t.Errorf("%s: expected %v, got %+v", tc.name, tc.expected, set)
+		}
+	}
+
+	set, err := parseMaterialSet(newMaterialLayerElement("ByEdge", "Direct", 1), faces)
+	if err != nil || set != nil {
+		t.Errorf("Expected unsupported mappings to be skipped, got %+v, %v", set, err)
+	}
+}
+
+func TestParseGeometryMaterialLayers(t *testing.T) {
+	element := newTestElement("Geometry", []*Property{newStringProperty("")},
+		newTestElement("Vertices", []*Property{newDoubleArrayProperty(0, 0, 0, 1, 0, 0, 1, 1, 0, 0, 1, 0, 2, 0, 0)}),
+		newTestElement("PolygonVertexIndex", []*Property{newIntArrayProperty(0, 1, 2, -4, 1, 4, -3)}),
+		newMaterialLayerElement("AllSame", "IndexToDirect", 1),
+		newMaterialLayerElement("ByPolygon", "Direct", 0, 2),
+		newMaterialLayerElement("ByEdge", "Direct", 1),
+	)
+	geom, err := parseGeometry(&Scene{}, element)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(geom.MaterialSets) != 2 {
+		t.Fatalf("Expected 2 material sets, got %d", len(geom.MaterialSets))
+	}
+	if !reflect.DeepEqual(geom.MaterialSets[1].Materials, []int{0, 2}) {
+		t.Errorf("Unexpected second material set %v", geom.MaterialSets[1].Materials)
+	}
+	// The first set fills one slot per triangle
+	if !reflect.DeepEqual(geom.Materials, []int{1, 1, 1}) {
+		t.Errorf("Expected AllSame to fill every triangle, got %v", geom.Materials)
+	}
+}
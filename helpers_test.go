@@ -0,0 +1,127 @@
+package ofbx
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadTestScene(t *testing.T, path string) *Scene {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	scene, err := Load(f)
+	require.NoError(t, err)
+	return scene
+}
+
+func loadTestSceneWithOptions(t *testing.T, path string, opts LoadOptions) *Scene {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	scene, err := LoadWithOptions(f, opts)
+	require.NoError(t, err)
+	return scene
+}
+
+// loadTestElements builds a scene from objects and connections the way Load does
+func loadTestElements(t *testing.T, objects []*Element, connections ...*Element) *Scene {
+	root := newTestRoot(objects, connections...)
+	scene := &Scene{ObjectMap: make(map[uint64]Obj), RootElement: root}
+	ok, err := parseConnection(root, scene)
+	require.True(t, ok, "%v", err)
+	ok, err = parseObjects(context.Background(), root, scene)
+	require.True(t, ok, "%v", err)
+	return scene
+}
+
+func assertPointsInDelta(t *testing.T, expected, actual floatgeom.Point3) {
+	for i := 0; i < 3; i++ {
+		assert.InDelta(t, expected[i], actual[i], 1e-6, "%v != %v", expected, actual)
+	}
+}
+
+func newDoubleArrayProperty(fs ...float64) *Property {
+	b := make([]byte, 8*len(fs))
+	for i, f := range fs {
+		binary.LittleEndian.PutUint64(b[i*8:], math.Float64bits(f))
+	}
+	return &Property{Type: ArrayDOUBLE, Count: len(fs), value: NewDataView(string(b))}
+}
+
+func newIntArrayProperty(is ...int32) *Property {
+	b := make([]byte, 4*len(is))
+	for i, v := range is {
+		binary.LittleEndian.PutUint32(b[i*4:], uint32(v))
+	}
+	return &Property{Type: ArrayINT, Count: len(is), value: NewDataView(string(b))}
+}
+
+func newTestElement(id string, props []*Property, children ...*Element) *Element {
+	return &Element{ID: NewDataView(id), Properties: props, Children: children}
+}
+
+// newTestRoot builds the root of a file with the given objects and connections
+func newTestRoot(objects []*Element, connections ...*Element) *Element {
+	return newTestElement("", nil,
+		newTestElement("Objects", nil, objects...),
+		newTestElement("Connections", nil, connections...),
+	)
+}
+
+func newP70(name, typ string, values ...*Property) *Element {
+	props := []*Property{newStringProperty(name), newStringProperty(typ), newStringProperty(""), newStringProperty("")}
+	return newTestElement("P", append(props, values...))
+}
+
+func newColorP70(name string, r, g, b float64) *Element {
+	return newP70(name, "Color", newDoubleProperty(r), newDoubleProperty(g), newDoubleProperty(b))
+}
+
+func newConnection(typ string, from, to int64, property ...string) *Element {
+	props := []*Property{newStringProperty(typ), newLongProperty(from), newLongProperty(to)}
+	for _, p := range property {
+		props = append(props, newStringProperty(p))
+	}
+	return newTestElement("C", props)
+}
+
+// newNamedObjectElement builds an object like `Geometry: 5, "name\x00\x01Geometry", "Mesh"`
+func newNamedObjectElement(id string, uid int64, name, class string, children ...*Element) *Element {
+	return newTestElement(id, []*Property{newLongProperty(uid), newStringProperty(name + "\x00\x01" + id), newStringProperty(class)}, children...)
+}
+
+func newObjectElement(id string, uid int64, class string, children ...*Element) *Element {
+	return newNamedObjectElement(id, uid, "", class, children...)
+}
+
+func newTriangleGeometry(uid int64, children ...*Element) *Element {
+	return newObjectElement("Geometry", uid, "Mesh", append([]*Element{
+		newTestElement("Vertices", []*Property{newDoubleArrayProperty(0, 0, 0, 1, 0, 0, 0, 1, 0)}),
+		newTestElement("PolygonVertexIndex", []*Property{newIntArrayProperty(0, 1, -3)}),
+	}, children...)...)
+}
+
+func newMaterialElement(id int64, props ...*Element) *Element {
+	return newNamedObjectElement("Material", id, "mat", "", newTestElement("Properties70", nil, props...))
+}
+
+func newTextureElement(id int64, children ...*Element) *Element {
+	return newNamedObjectElement("Texture", id, "tex", "", children...)
+}
+
+func newVideoElement(id int64, filename string, content string) *Element {
+	video := newNamedObjectElement("Video", id, "video", "Clip",
+		newTestElement("Filename", []*Property{newStringProperty(filename)}),
+	)
+	if content != "" {
+		video.Children = append(video.Children, newTestElement("Content", []*Property{{Type: RAWSTRING, value: NewDataView(content)}}))
+	}
+	return video
+}
@@ -0,0 +1,111 @@
+package ofbx
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register gif for Texture.Image
+	_ "image/jpeg" // Register jpeg for Texture.Image
+	_ "image/png"  // Register png for Texture.Image
+	"io/fs"
+	"path"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Image decodes the texture's image, from the embedded media if there is some and from fsys otherwise,
+// resolving its path like NewTextureResolver(fsys) does. PNG, JPEG, GIF, TGA and DDS are supported.
+// Images are cached by the scene, keyed by fsys and their path in it, unless fsys can't be compared.
+func (t *Texture) Image(fsys fs.FS) (image.Image, error) {
+	name, content := t.Media()
+	var key imageKey
+	if len(content) != 0 {
+		key.path = fmt.Sprintf("video:%d", t.video.ID())
+	} else {
+		if fsys == nil {
+			return nil, errors.Errorf("Texture %q has no embedded media", t.Name())
+		}
+		p, err := NewTextureResolver(fsys).Resolve(t)
+		if err != nil {
+			return nil, err
+		}
+		name, key = p, imageKey{fsys: fsys, path: p}
+	}
+
+	scene := t.Scene()
+	if scene == nil || (key.fsys != nil && !reflect.TypeOf(key.fsys).Comparable()) {
+		return loadImage(fsys, name, key.path, content)
+	}
+	scene.imagesMu.Lock()
+	entry, ok := scene.images[key]
+	if !ok {
+		if scene.images == nil {
+			scene.images = make(map[imageKey]*imageEntry)
+		}
+		entry = &imageEntry{done: make(chan struct{})}
+		scene.images[key] = entry
+	}
+	scene.imagesMu.Unlock()
+	if ok {
+		<-entry.done
+		return entry.img, entry.err
+	}
+
+	entry.img, entry.err = loadImage(fsys, name, key.path, content)
+	if entry.err != nil {
+		// Let later calls try again, the file may only be missing for now
+		scene.imagesMu.Lock()
+		delete(scene.images, key)
+		scene.imagesMu.Unlock()
+	}
+	close(entry.done)
+	return entry.img, entry.err
+}
+
+// imageKey identifies an image of the scene's cache, embedded ones have no fsys
+type imageKey struct {
+	fsys fs.FS
+	path string
+}
+
+// imageEntry is an image of the scene's cache, which is being loaded until done is closed
+type imageEntry struct {
+	done chan struct{}
+	img  image.Image
+	err  error
+}
+
+// loadImage reads the image at p in fsys unless its content is given, and decodes it
+func loadImage(fsys fs.FS, name, p string, content []byte) (image.Image, error) {
+	if len(content) == 0 {
+		var err error
+		content, err = fs.ReadFile(fsys, p)
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to read texture")
+		}
+	}
+	img, err := decodeImage(name, content)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to decode texture %q", name)
+	}
+	return img, nil
+}
+
+// decodeImage decodes data with the format the data's signature or the name's extension tells.
+// TGA files without a footer have no signature, so data no other format claims is tried as TGA.
+func decodeImage(name string, data []byte) (image.Image, error) {
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(name, "\\", "/")))
+	switch {
+	case bytes.HasPrefix(data, []byte(ddsMagic)):
+		return decodeDDS(data)
+	case ext == ".tga", bytes.HasSuffix(data, []byte(tgaFooterSignature)):
+		return decodeTGA(data)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err == image.ErrFormat && isTGAHeader(data) {
+		return decodeTGA(data)
+	}
+	return img, err
+}
@@ -0,0 +1,155 @@
+package ofbx
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextureImageFromFile(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	fsys := os.DirFS("testdata")
+	decoded := 0
+	for _, obj := range scene.ObjectMap {
+		tex, ok := obj.(*Texture)
+		if !ok {
+			continue
+		}
+		img, err := tex.Image(fsys)
+		require.NoError(t, err)
+		assert.False(t, img.Bounds().Empty())
+		again, err := tex.Image(fsys)
+		require.NoError(t, err)
+		assert.Same(t, img.(*image.YCbCr), again.(*image.YCbCr))
+		decoded++
+	}
+	assert.NotZero(t, decoded)
+	assert.NotEmpty(t, scene.images)
+
+	_, err := NewTexture(scene, &Element{ID: NewDataView("Texture")}).Image(fstest.MapFS{})
+	assert.Error(t, err)
+}
+
+func TestTextureImageEmbedded(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+	src.SetNRGBA(0, 0, color.NRGBA{10, 20, 30, 255})
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, src))
+
+	scene := loadTestElements(t, []*Element{
+		newTextureElement(1),
+		newVideoElement(2, "embedded.png", buf.String()),
+		newTextureElement(3),
+		newVideoElement(4, "broken.tga", "not an image"),
+	}, newConnection("OO", 2, 1), newConnection("OO", 4, 3))
+
+	// No file system is needed for embedded media
+	img, err := scene.ObjectMap[1].(*Texture).Image(nil)
+	require.NoError(t, err)
+	r, g, b, _ := img.At(0, 0).RGBA()
+	assert.Equal(t, []uint32{10, 20, 30}, []uint32{r >> 8, g >> 8, b >> 8})
+
+	_, err = scene.ObjectMap[3].(*Texture).Image(nil)
+	assert.Error(t, err)
+}
+
+func TestTextureImagePerFS(t *testing.T) {
+	encode := func(c color.NRGBA) []byte {
+		src := image.NewNRGBA(image.Rect(0, 0, 1, 1))
+		src.SetNRGBA(0, 0, c)
+		var buf bytes.Buffer
+		require.NoError(t, png.Encode(&buf, src))
+		return buf.Bytes()
+	}
+	red, blue := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(red, "a.png"), encode(color.NRGBA{255, 0, 0, 255}), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(blue, "a.png"), encode(color.NRGBA{0, 0, 255, 255}), 0o644))
+
+	scene := loadTestElements(t, []*Element{
+		newTextureElement(1, newTestElement("FileName", []*Property{newStringProperty("a.png")})),
+	})
+	tex := scene.ObjectMap[1].(*Texture)
+
+	// The same path in another file system is another image
+	img, err := tex.Image(os.DirFS(red))
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{255, 0, 0, 255}, img.At(0, 0))
+	img, err = tex.Image(os.DirFS(blue))
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{0, 0, 255, 255}, img.At(0, 0))
+
+	// File systems that can't be map keys aren't cached
+	img, err = tex.Image(fstest.MapFS{"a.png": {Data: encode(color.NRGBA{0, 255, 0, 255})}})
+	require.NoError(t, err)
+	assert.Equal(t, color.RGBA{0, 255, 0, 255}, img.At(0, 0))
+	assert.Len(t, scene.images, 2)
+}
+
+// blockingFS holds back reading name until release is closed, and counts how often it is read
+type blockingFS struct {
+	fs.FS
+	name    string
+	reads   int32
+	opened  chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingFS) ReadFile(name string) ([]byte, error) {
+	if name == b.name && atomic.AddInt32(&b.reads, 1) == 1 {
+		close(b.opened)
+		<-b.release
+	}
+	return fs.ReadFile(b.FS, name)
+}
+
+func TestTextureImageConcurrent(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	slow := scene.ObjectMap[1364085717056].(*Texture)
+	fast := scene.ObjectMap[1364085724256].(*Texture)
+	fsys := &blockingFS{FS: os.DirFS("testdata"), name: "Ro_Br_50cm.jpg", opened: make(chan struct{}), release: make(chan struct{})}
+
+	type result struct {
+		img image.Image
+		err error
+	}
+	results := make(chan result, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			img, err := slow.Image(fsys)
+			results <- result{img, err}
+		}()
+	}
+	<-fsys.opened
+
+	// Other images load while one is still being read
+	done := make(chan error, 1)
+	go func() {
+		_, err := fast.Image(fsys)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("loading an image waited for another one")
+	}
+
+	// Both callers of the image being read share it, and it is only read once
+	close(fsys.release)
+	first, second := <-results, <-results
+	require.NoError(t, first.err)
+	require.NoError(t, second.err)
+	assert.Same(t, first.img.(*image.YCbCr), second.img.(*image.YCbCr))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fsys.reads))
+}
@@ -0,0 +1,179 @@
+package ofbx
+
+import (
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+)
+
+// UVSet is a named set of texture coordinates, holding one value per polygon vertex
+type UVSet struct {
+	Name string
+	// Index is the set's index among the geometry's LayerElementUV, as referenced by Layers
+	Index int
+	UVs   []floatgeom.Point2
+}
+
+// ColorSet is a named set of vertex colors, holding one value per polygon vertex
+type ColorSet struct {
+	Name   string
+	Index  int
+	Colors []floatgeom.Point4
+}
+
+// VectorSet is a named set of normals, tangents or binormals, holding one value per polygon vertex
+type VectorSet struct {
+	Name    string
+	Index   int
+	Vectors []floatgeom.Point3
+}
+
+// MaterialSet is a named set of material slots, holding one value per polygon.
+// Slots index the materials of the mesh using the geometry, -1 meaning none.
+type MaterialSet struct {
+	Name      string
+	Index     int
+	Materials []int
+}
+
+// Layer groups the layer elements that are meant to be used together
+type Layer struct {
+	Index    int
+	Elements []LayerElementRef
+}
+
+// LayerElementRef points to a layer element by its type, such as "LayerElementUV", and its index among
+// the elements of that type
+type LayerElementRef struct {
+	Type       string
+	TypedIndex int
+}
+
+// Element returns the index of the layer's element of the given type
+func (l Layer) Element(typ string) (int, bool) {
+	for _, ref := range l.Elements {
+		if ref.Type == typ {
+			return ref.TypedIndex, true
+		}
+	}
+	return 0, false
+}
+
+// UVSetByName returns the UV set with the given name, or nil if there is none
+func (g *Geometry) UVSetByName(name string) *UVSet {
+	for i := range g.UVSets {
+		if g.UVSets[i].Name == name {
+			return &g.UVSets[i]
+		}
+	}
+	return nil
+}
+
+func layerElementIndex(element *Element) int {
+	if len(element.Properties) > 0 {
+		return int(element.Properties[0].value.toInt32())
+	}
+	return 0
+}
+
+func layerElementName(element *Element) string {
+	if name := findSingleChildProperty(element, "Name"); name != nil {
+		return name.value.String()
+	}
+	return ""
+}
+
+func parseLayers(element *Element) []Layer {
+	layers := make([]Layer, 0)
+	for _, child := range element.Children {
+		if child.ID.String() != "Layer" {
+			continue
+		}
+		layer := Layer{Index: layerElementIndex(child)}
+		for _, elem := range child.Children {
+			if elem.ID.String() != "LayerElement" {
+				continue
+			}
+			typ := findSingleChildProperty(elem, "Type")
+			if typ == nil {
+				continue
+			}
+			ref := LayerElementRef{Type: typ.value.String()}
+			if idx := findSingleChildProperty(elem, "TypedIndex"); idx != nil {
+				ref.TypedIndex = int(idx.value.toInt32())
+			}
+			layer.Elements = append(layer.Elements, ref)
+		}
+		layers = append(layers, layer)
+	}
+	return layers
+}
+
+// vectorData returns Normals, Tangents, Binormals and the data of every vector set, each backing array once
+func (g *Geometry) vectorData() [][]floatgeom.Point3 {
+	all := [][]floatgeom.Point3{g.Normals, g.Tangents, g.Binormals}
+	for _, sets := range [][]VectorSet{g.NormalSets, g.TangentSets, g.BinormalSets} {
+		for _, set := range sets {
+			all = append(all, set.Vectors)
+		}
+	}
+	out := make([][]floatgeom.Point3, 0, len(all))
+	seen := make(map[*floatgeom.Point3]bool)
+	for _, data := range all {
+		if len(data) == 0 || seen[&data[0]] {
+			continue
+		}
+		seen[&data[0]] = true
+		out = append(out, data)
+	}
+	return out
+}
+
+// uvData returns UVs and the data of every UV set, each backing array once
+func (g *Geometry) uvData() [][]floatgeom.Point2 {
+	all := append([][]floatgeom.Point2{}, g.UVs[:]...)
+	for _, set := range g.UVSets {
+		all = append(all, set.UVs)
+	}
+	out := make([][]floatgeom.Point2, 0, len(all))
+	seen := make(map[*floatgeom.Point2]bool)
+	for _, data := range all {
+		if len(data) == 0 || seen[&data[0]] {
+			continue
+		}
+		seen[&data[0]] = true
+		out = append(out, data)
+	}
+	return out
+}
+
+// colorData returns Colors and the data of every color set, each backing array once
+func (g *Geometry) colorData() [][]floatgeom.Point4 {
+	all := [][]floatgeom.Point4{g.Colors}
+	for _, set := range g.ColorSets {
+		all = append(all, set.Colors)
+	}
+	out := make([][]floatgeom.Point4, 0, len(all))
+	seen := make(map[*floatgeom.Point4]bool)
+	for _, data := range all {
+		if len(data) == 0 || seen[&data[0]] {
+			continue
+		}
+		seen[&data[0]] = true
+		out = append(out, data)
+	}
+	return out
+}
+
+// bitangentSigns derives the handedness of every polygon vertex from its normal, tangent and binormal
+func bitangentSigns(normals, tangents, binormals []floatgeom.Point3) []float64 {
+	if len(normals) != len(tangents) || len(normals) != len(binormals) {
+		return nil
+	}
+	signs := make([]float64, len(normals))
+	for i, n := range normals {
+		signs[i] = 1
+		if n.Cross(tangents[i]).Dot(binormals[i]) < 0 {
+			signs[i] = -1
+		}
+	}
+	return signs
+}
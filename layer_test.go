@@ -0,0 +1,90 @@
+package ofbx
+
+import (
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLayerElement(id string, index int32, name, dataName string, data ...float64) *Element {
+	return newTestElement(id, []*Property{newIntegerProperty(index)},
+		newTestElement("Name", []*Property{newStringProperty(name)}),
+		newTestElement("MappingInformationType", []*Property{newStringProperty("ByPolygonVertex")}),
+		newTestElement("ReferenceInformationType", []*Property{newStringProperty("Direct")}),
+		newTestElement(dataName, []*Property{newDoubleArrayProperty(data...)}),
+	)
+}
+
+func TestParseLayerSets(t *testing.T) {
+	element := newTestElement("Geometry", []*Property{newStringProperty("")},
+		newTestElement("Vertices", []*Property{newDoubleArrayProperty(0, 0, 0, 1, 0, 0, 0, 1, 0)}),
+		newTestElement("PolygonVertexIndex", []*Property{newIntArrayProperty(0, 1, -3)}),
+		newLayerElement("LayerElementNormal", 0, "", "Normals", 0, 0, 1, 0, 0, 1, 0, 0, 1),
+		newLayerElement("LayerElementTangent", 0, "", "Tangents", 1, 0, 0, 1, 0, 0, 1, 0, 0),
+		newLayerElement("LayerElementBinormal", 0, "", "Binormals", 0, -1, 0, 0, -1, 0, 0, -1, 0),
+		newLayerElement("LayerElementUV", 0, "map1", "UV", 0, 0, 1, 0, 0, 1),
+		newLayerElement("LayerElementUV", 5, "lightmap", "UV", 0.5, 0.5, 1, 0.5, 0.5, 1),
+		newLayerElement("LayerElementColor", 0, "colorSet1", "Colors", 1, 0, 0, 1, 0, 1, 0, 1, 0, 0, 1, 1),
+		newLayerElement("LayerElementColor", 1, "colorSet2", "Colors", 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1),
+		newTestElement("Layer", []*Property{newIntegerProperty(0)},
+			newTestElement("LayerElement", nil,
+				newTestElement("Type", []*Property{newStringProperty("LayerElementUV")}),
+				newTestElement("TypedIndex", []*Property{newIntegerProperty(0)}),
+			),
+		),
+		newTestElement("Layer", []*Property{newIntegerProperty(1)},
+			newTestElement("LayerElement", nil,
+				newTestElement("Type", []*Property{newStringProperty("LayerElementUV")}),
+				newTestElement("TypedIndex", []*Property{newIntegerProperty(5)}),
+			),
+			newTestElement("LayerElement", nil,
+				newTestElement("Type", []*Property{newStringProperty("LayerElementColor")}),
+				newTestElement("TypedIndex", []*Property{newIntegerProperty(1)}),
+			),
+		),
+	)
+	geom, err := parseGeometry(&Scene{}, element)
+	require.NoError(t, err)
+
+	require.Len(t, geom.UVSets, 2)
+	assert.Equal(t, "lightmap", geom.UVSets[1].Name)
+	assert.Equal(t, 5, geom.UVSets[1].Index)
+	assert.Equal(t, []floatgeom.Point2{{0.5, 0.5}, {1, 0.5}, {0.5, 1}}, geom.UVSetByName("lightmap").UVs)
+	assert.Nil(t, geom.UVSetByName("missing"))
+	assert.Equal(t, geom.UVSets[0].UVs, geom.UVs[0])
+
+	require.Len(t, geom.ColorSets, 2)
+	assert.Equal(t, "colorSet2", geom.ColorSets[1].Name)
+	assert.Equal(t, floatgeom.Point4{1, 0, 0, 1}, geom.Colors[0])
+
+	require.Len(t, geom.BinormalSets, 1)
+	assert.Equal(t, floatgeom.Point3{0, -1, 0}, geom.Binormals[0])
+	assert.Equal(t, floatgeom.Point3{1, 0, 0}, geom.Tangents[0])
+	// The file's binormals point against cross(normal, tangent)
+	assert.Equal(t, []float64{-1, -1, -1}, geom.BitangentSigns)
+
+	require.Len(t, geom.Layers, 2)
+	idx, ok := geom.Layers[1].Element("LayerElementColor")
+	assert.True(t, ok)
+	assert.Equal(t, 1, idx)
+	_, ok = geom.Layers[0].Element("LayerElementColor")
+	assert.False(t, ok)
+}
+
+func TestLayerSetsFollowConversion(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	geom := scene.Meshes[0].Geometry
+	require.Len(t, geom.UVSets, 1)
+	assert.Equal(t, "map1", geom.UVSets[0].Name)
+	require.Len(t, geom.Layers, 1)
+	assert.Len(t, geom.Layers[0].Elements, 3)
+
+	normal := geom.Normals[1]
+	require.NoError(t, scene.ConvertAxisSystem(AxisSystemYUpLeftHanded))
+	// Data shared between the sets and the fields is converted and reordered once
+	assert.Equal(t, floatgeom.Point3{normal[0], normal[1], -normal[2]}, geom.Normals[len(geom.Faces[0])-1])
+	assert.Equal(t, geom.NormalSets[0].Vectors, geom.Normals)
+	assert.Equal(t, geom.UVSets[0].UVs, geom.UVs[0])
+}
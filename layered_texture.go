@@ -0,0 +1,100 @@
+package ofbx
+
+import "fmt"
+
+// BlendMode tells how a layer of a LayeredTexture is combined with the layers under it
+type BlendMode int
+
+// BlendMode options
+const (
+	BlendTranslucent  BlendMode = iota
+	BlendAdditive     BlendMode = iota
+	BlendModulate     BlendMode = iota
+	BlendModulate2    BlendMode = iota
+	BlendOver         BlendMode = iota
+	BlendNormal       BlendMode = iota
+	BlendDissolve     BlendMode = iota
+	BlendDarken       BlendMode = iota
+	BlendColorBurn    BlendMode = iota
+	BlendLinearBurn   BlendMode = iota
+	BlendDarkerColor  BlendMode = iota
+	BlendLighten      BlendMode = iota
+	BlendScreen       BlendMode = iota
+	BlendColorDodge   BlendMode = iota
+	BlendLinearDodge  BlendMode = iota
+	BlendLighterColor BlendMode = iota
+	BlendSoftLight    BlendMode = iota
+	BlendHardLight    BlendMode = iota
+	BlendVividLight   BlendMode = iota
+	BlendLinearLight  BlendMode = iota
+	BlendPinLight     BlendMode = iota
+	BlendHardMix      BlendMode = iota
+	BlendDifference   BlendMode = iota
+	BlendExclusion    BlendMode = iota
+	BlendSubtract     BlendMode = iota
+	BlendDivide       BlendMode = iota
+	BlendHue          BlendMode = iota
+	BlendSaturation   BlendMode = iota
+	BlendColor        BlendMode = iota
+	BlendLuminosity   BlendMode = iota
+	BlendOverlay      BlendMode = iota
+)
+
+// LayeredTexture blends several textures together
+type LayeredTexture struct {
+	Object
+	// Textures holds the layers in connection order, the first one being the base layer
+	Textures []*Texture
+	// BlendModes and Alphas hold the blend mode and opacity of every layer
+	BlendModes []BlendMode
+	Alphas     []float64
+}
+
+// NewLayeredTexture creates a stub LayeredTexture
+func NewLayeredTexture(scene *Scene, element *Element) *LayeredTexture {
+	return &LayeredTexture{
+		Object: *NewObject(scene, element),
+	}
+}
+
+// Type returns LAYERED_TEXTURE
+func (lt *LayeredTexture) Type() Type {
+	return LAYERED_TEXTURE
+}
+
+// BlendMode returns the blend mode of a layer, BlendNormal when the file doesn't say
+func (lt *LayeredTexture) BlendMode(layer int) BlendMode {
+	if layer < 0 || layer >= len(lt.BlendModes) {
+		return BlendNormal
+	}
+	return lt.BlendModes[layer]
+}
+
+// Alpha returns the opacity of a layer, 1 when the file doesn't say
+func (lt *LayeredTexture) Alpha(layer int) float64 {
+	if layer < 0 || layer >= len(lt.Alphas) {
+		return 1
+	}
+	return lt.Alphas[layer]
+}
+
+// BaseTexture flattens the layered texture to its base layer for consumers that can't blend, or nil
+// if it has no layers
+func (lt *LayeredTexture) BaseTexture() *Texture {
+	if len(lt.Textures) == 0 {
+		return nil
+	}
+	return lt.Textures[0]
+}
+
+func (lt *LayeredTexture) String() string {
+	return lt.stringPrefix("")
+}
+
+func (lt *LayeredTexture) stringPrefix(prefix string) string {
+	s := prefix + "LayeredTexture: " + lt.Object.String() + "\n"
+	for i, t := range lt.Textures {
+		s += prefix + fmt.Sprintf("\tLayer %d (mode %d, alpha %f): ", i, lt.BlendMode(i), lt.Alpha(i)) + t.String() + "\n"
+	}
+	return s
+}
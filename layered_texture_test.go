@@ -0,0 +1,40 @@
+package ofbx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayeredTexture(t *testing.T) {
+	objects := []*Element{
+		newMaterialElement(1),
+		newNamedObjectElement("LayeredTexture", 2, "layers", "",
+			newTestElement("BlendModes", []*Property{newIntArrayProperty(5, 2)}),
+			newTestElement("Alphas", []*Property{newDoubleArrayProperty(1, 0.5)}),
+		),
+		newNamedObjectElement("Texture", 3, "base", ""),
+		newNamedObjectElement("Texture", 4, "dirt", ""),
+	}
+	scene := loadTestElements(t, objects,
+		newConnection("OO", 3, 2),
+		newConnection("OO", 4, 2),
+		newConnection("OP", 2, 1, "DiffuseColor"),
+	)
+
+	lt, ok := scene.ObjectMap[2].(*LayeredTexture)
+	require.True(t, ok)
+	require.Len(t, lt.Textures, 2)
+	assert.Same(t, scene.ObjectMap[3], lt.Textures[0])
+	assert.Same(t, scene.ObjectMap[4], lt.Textures[1])
+	assert.Equal(t, BlendModulate, lt.BlendMode(1))
+	assert.Equal(t, 0.5, lt.Alpha(1))
+	assert.Equal(t, BlendNormal, lt.BlendMode(2))
+	assert.Equal(t, 1.0, lt.Alpha(2))
+
+	mat := scene.ObjectMap[1].(*Material)
+	assert.Equal(t, []*LayeredTexture{lt}, mat.LayeredTextures["DiffuseColor"])
+	assert.Same(t, lt.BaseTexture(), mat.GetTexture(DIFFUSE))
+	assert.Nil(t, NewLayeredTexture(scene, &Element{ID: NewDataView("LayeredTexture")}).BaseTexture())
+}
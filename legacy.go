@@ -0,0 +1,300 @@
+package ofbx
+
+import "strings"
+
+// legacyVersion is the first FBX version that identifies objects by ID. Older files, such as FBX 6.1,
+// identify objects by name, embed geometry in models, use Properties60 and keep animation in Takes.
+const legacyVersion = 7000
+
+// legacyTransformChannels maps the channels of a legacy take's Transform to model properties
+var legacyTransformChannels = []struct {
+	channel, property string
+	defaultVal        float64
+}{
+	{"T", BoneTranslate, 0},
+	{"R", BoneRotate, 0},
+	{"S", BoneScale, 1},
+}
+
+// legacyConverter rewrites a legacy element tree into the layout of FBX 7, so the rest of the parser
+// can handle it unchanged
+type legacyConverter struct {
+	ids         map[string]uint64
+	nextID      uint64
+	geometries  map[uint64]uint64
+	skins       map[uint64]bool
+	connections []*Element
+}
+
+// convertLegacy rewrites the objects, connections and takes of a pre 7000 file in place
+func convertLegacy(root *Element) {
+	c := &legacyConverter{
+		// The scene's root model is object 0, the root node
+		ids:        map[string]uint64{"Model::Scene": 0},
+		nextID:     1,
+		geometries: make(map[uint64]uint64),
+		skins:      make(map[uint64]bool),
+	}
+	objects := findChildren(root, "Objects")
+	if len(objects) == 0 {
+		return
+	}
+	converted := make([]*Element, 0, len(objects[0].Children))
+	for _, elem := range objects[0].Children {
+		if elem.ID.String() == "GlobalSettings" {
+			convertProperties60(elem)
+			root.Children = append(root.Children, elem)
+			continue
+		}
+		if !isString(elem.getProperty(0)) {
+			continue
+		}
+		converted = append(converted, c.convertObject(elem)...)
+	}
+	c.convertConnections(root)
+	converted = append(converted, c.convertTakes(root)...)
+	objects[0].Children = converted
+
+	connections := findChildren(root, "Connections")
+	if len(connections) == 0 {
+		root.Children = append(root.Children, &Element{ID: NewDataView("Connections")})
+		connections = findChildren(root, "Connections")
+	}
+	connections[0].Children = c.connections
+}
+
+func (c *legacyConverter) newID() uint64 {
+	id := c.nextID
+	c.nextID++
+	return id
+}
+
+// splitLegacyName splits a name such as "Model::Cube" into its class and object name
+func splitLegacyName(name string) (class, object string) {
+	if i := strings.Index(name, "::"); i >= 0 {
+		return name[:i], name[i+2:]
+	}
+	return "", name
+}
+
+func isLegacyGeometryElement(id string) bool {
+	switch id {
+	case "Vertices", "PolygonVertexIndex", "Edges", "GeometryVersion", "Layer":
+		return true
+	}
+	return strings.HasPrefix(id, "LayerElement")
+}
+
+func (c *legacyConverter) convertObject(elem *Element) []*Element {
+	fullName := elem.getProperty(0).value.String()
+	subclass := ""
+	if prop := elem.getProperty(1); prop != nil {
+		subclass = prop.value.String()
+	}
+	id := c.newID()
+	if _, ok := c.ids[fullName]; !ok {
+		c.ids[fullName] = id
+	}
+	class, name := splitLegacyName(fullName)
+	objectID := elem.ID.String()
+	if objectID == "Model" && subclass == "Limb" {
+		subclass = "LimbNode"
+	}
+	elem.Properties = []*Property{newLongProperty(int64(id)), newStringProperty(name + "\x00\x01" + class), newStringProperty(subclass)}
+	convertProperties60(elem)
+	out := []*Element{elem}
+
+	switch {
+	case objectID == "Model" && subclass == "Mesh":
+		gid := c.newID()
+		geom := &Element{
+			ID:         NewDataView("Geometry"),
+			Properties: []*Property{newLongProperty(int64(gid)), newStringProperty(name + "\x00\x01Geometry"), newStringProperty("Mesh")},
+		}
+		kept := make([]*Element, 0, len(elem.Children))
+		for _, child := range elem.Children {
+			if isLegacyGeometryElement(child.ID.String()) {
+				geom.Children = append(geom.Children, child)
+			} else {
+				kept = append(kept, child)
+			}
+		}
+		elem.Children = kept
+		c.geometries[id] = gid
+		c.connect("OO", gid, id)
+		out = append(out, geom)
+	case objectID == "Deformer" && subclass == "Skin":
+		c.skins[id] = true
+	}
+	return out
+}
+
+// convertProperties60 turns Property entries, which have no label before their flags, into P entries
+func convertProperties60(elem *Element) {
+	for _, child := range elem.Children {
+		if child.ID.String() != "Properties60" {
+			continue
+		}
+		child.ID = NewDataView("Properties70")
+		for _, p := range child.Children {
+			if p.ID.String() != "Property" || len(p.Properties) < 3 {
+				continue
+			}
+			p.ID = NewDataView("P")
+			props := make([]*Property, 0, len(p.Properties)+1)
+			props = append(props, p.Properties[0], p.Properties[1], newStringProperty(""))
+			p.Properties = append(props, p.Properties[2:]...)
+		}
+	}
+}
+
+func (c *legacyConverter) connect(typ string, from, to uint64, property ...string) {
+	props := []*Property{newStringProperty(typ), newLongProperty(int64(from)), newLongProperty(int64(to))}
+	for _, p := range property {
+		props = append(props, newStringProperty(p))
+	}
+	c.connections = append(c.connections, &Element{ID: NewDataView("C"), Properties: props})
+}
+
+// convertConnections maps Connect entries between names onto IDs. Skins connect to the model in
+// legacy files and to its geometry in FBX 7.
+func (c *legacyConverter) convertConnections(root *Element) {
+	connections := findChildren(root, "Connections")
+	if len(connections) == 0 {
+		return
+	}
+	for _, conn := range connections[0].Children {
+		typ, from, to := conn.getProperty(0), conn.getProperty(1), conn.getProperty(2)
+		if conn.ID.String() != "Connect" || !isString(typ) || !isString(from) || !isString(to) {
+			continue
+		}
+		fromID, ok := c.ids[from.value.String()]
+		if !ok {
+			continue
+		}
+		toID, ok := c.ids[to.value.String()]
+		if !ok {
+			continue
+		}
+		if gid, ok := c.geometries[toID]; ok && c.skins[fromID] {
+			toID = gid
+		}
+		switch typ.value.String() {
+		case "OO":
+			c.connect("OO", fromID, toID)
+		case "OP":
+			if prop := conn.getProperty(3); isString(prop) {
+				c.connect("OP", fromID, toID, prop.value.String())
+			}
+		}
+	}
+}
+
+// convertTakes creates an animation stack with a single layer for every take, and curve nodes for the
+// transform channels of its models
+func (c *legacyConverter) convertTakes(root *Element) []*Element {
+	takes := findChildren(root, "Takes")
+	if len(takes) == 0 {
+		return nil
+	}
+	objects := make([]*Element, 0)
+	for _, take := range takes[0].Children {
+		if take.ID.String() != "Take" || !isString(take.getProperty(0)) {
+			continue
+		}
+		stackID, layerID := c.newID(), c.newID()
+		stack := &Element{
+			ID:         NewDataView("AnimationStack"),
+			Properties: []*Property{newLongProperty(int64(stackID)), newStringProperty(take.getProperty(0).value.String() + "\x00\x01AnimStack"), newStringProperty("")},
+		}
+		if localTime := findChildProperty(take, "LocalTime"); len(localTime) >= 2 && isLong(localTime[0]) && isLong(localTime[1]) {
+			stack.Children = append(stack.Children, &Element{ID: NewDataView("Properties70"), Children: []*Element{
+				{ID: NewDataView("P"), Properties: []*Property{newStringProperty("LocalStart"), newStringProperty("KTime"), newStringProperty("Time"), newStringProperty(""), localTime[0]}},
+				{ID: NewDataView("P"), Properties: []*Property{newStringProperty("LocalStop"), newStringProperty("KTime"), newStringProperty("Time"), newStringProperty(""), localTime[1]}},
+			}})
+		}
+		layer := &Element{
+			ID:         NewDataView("AnimationLayer"),
+			Properties: []*Property{newLongProperty(int64(layerID)), newStringProperty("BaseLayer\x00\x01AnimLayer"), newStringProperty("")},
+		}
+		objects = append(objects, stack, layer)
+		c.connect("OO", layerID, stackID)
+
+		for _, model := range take.Children {
+			if model.ID.String() != "Model" || !isString(model.getProperty(0)) {
+				continue
+			}
+			modelID, ok := c.ids[model.getProperty(0).value.String()]
+			if !ok {
+				continue
+			}
+			transform := findLegacyChannel(model, "Transform")
+			if transform == nil {
+				continue
+			}
+			for _, tc := range legacyTransformChannels {
+				channel := findLegacyChannel(transform, tc.channel)
+				if channel == nil {
+					continue
+				}
+				nodeID := c.newID()
+				objects = append(objects, &Element{
+					ID:         NewDataView("AnimationCurveNode"),
+					Properties: []*Property{newLongProperty(int64(nodeID)), newStringProperty(tc.channel + "\x00\x01AnimCurveNode"), newStringProperty("")},
+				})
+				c.connect("OO", nodeID, layerID)
+				c.connect("OP", nodeID, modelID, tc.property)
+				// Every axis gets a curve, as curve nodes take their curves in connection order
+				for _, axis := range []string{"X", "Y", "Z"} {
+					times, values := legacyKeys(findLegacyChannel(channel, axis), tc.defaultVal)
+					curveID := c.newID()
+					objects = append(objects, &Element{
+						ID:         NewDataView("AnimationCurve"),
+						Properties: []*Property{newLongProperty(int64(curveID)), newStringProperty("\x00\x01AnimCurve"), newStringProperty("")},
+						Children: []*Element{
+							{ID: NewDataView("KeyTime"), Properties: []*Property{newLongArrayProperty(times)}},
+							{ID: NewDataView("KeyValueFloat"), Properties: []*Property{newFloatArrayProperty(values)}},
+						},
+					})
+					c.connect("OP", curveID, nodeID, "d|"+axis)
+				}
+			}
+		}
+	}
+	return objects
+}
+
+func findLegacyChannel(elem *Element, name string) *Element {
+	for _, child := range elem.Children {
+		if child.ID.String() == "Channel" && isString(child.getProperty(0)) && child.getProperty(0).value.String() == name {
+			return child
+		}
+	}
+	return nil
+}
+
+// legacyKeys reads a channel's keys, which are a flat list of a time, a value, an interpolation
+// and its parameters for every key. Channels without keys hold their Default at time 0.
+func legacyKeys(channel *Element, defaultVal float64) ([]int64, []float32) {
+	if channel == nil {
+		return []int64{0}, []float32{float32(defaultVal)}
+	}
+	if def := findSingleChildProperty(channel, "Default"); def != nil {
+		defaultVal = def.toFloat64()
+	}
+	times, values := make([]int64, 0), make([]float32, 0)
+	keys := findChildProperty(channel, "Key")
+	for i := 0; i+1 < len(keys); i++ {
+		// Interpolation parameters are strings and numbers, but never times
+		if keys[i].Type != LONG {
+			continue
+		}
+		times = append(times, keys[i].value.toint64())
+		values = append(values, float32(keys[i+1].toFloat64()))
+		i++
+	}
+	if len(times) == 0 {
+		return []int64{0}, []float32{float32(defaultVal)}
+	}
+	return times, values
+}
@@ -0,0 +1,146 @@
+package ofbx
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newProperty60(name, typ string, values ...*Property) *Element {
+	return newTestElement("Property", append([]*Property{newStringProperty(name), newStringProperty(typ), newStringProperty("A")}, values...))
+}
+
+func newLegacyConnect(typ, from, to string, property ...string) *Element {
+	props := []*Property{newStringProperty(typ), newStringProperty(from), newStringProperty(to)}
+	for _, p := range property {
+		props = append(props, newStringProperty(p))
+	}
+	return newTestElement("Connect", props)
+}
+
+func newLegacyChannel(name string, children ...*Element) *Element {
+	return newTestElement("Channel", []*Property{newStringProperty(name)}, children...)
+}
+
+// newLegacyScene builds the element tree of an FBX 6.1 file with a skinned, animated cube
+func newLegacyScene() *Element {
+	second := int64(46186158000)
+	return newTestElement("", nil,
+		newTestElement("Objects", nil,
+			newTestElement("Model", []*Property{newStringProperty("Model::Cube"), newStringProperty("Mesh")},
+				newTestElement("Version", []*Property{newIntegerProperty(232)}),
+				newTestElement("Properties60", nil,
+					newProperty60("Lcl Translation", "Lcl Translation", newDoubleProperty(1), newDoubleProperty(2), newDoubleProperty(3)),
+				),
+				newTestElement("Vertices", []*Property{newDoubleArrayProperty(0, 0, 0, 1, 0, 0, 0, 1, 0)}),
+				newTestElement("PolygonVertexIndex", []*Property{newIntArrayProperty(0, 1, -3)}),
+				newTestElement("GeometryVersion", []*Property{newIntegerProperty(124)}),
+			),
+			newTestElement("Model", []*Property{newStringProperty("Model::Bone"), newStringProperty("Limb")}),
+			newTestElement("Material", []*Property{newStringProperty("Material::red"), newStringProperty("")},
+				newTestElement("ShadingModel", []*Property{newStringProperty("lambert")}),
+				newTestElement("Properties60", nil,
+					newProperty60("DiffuseColor", "ColorRGB", newDoubleProperty(1), newDoubleProperty(0), newDoubleProperty(0)),
+				),
+			),
+			newTestElement("Deformer", []*Property{newStringProperty("Deformer::Skin Cube"), newStringProperty("Skin")}),
+			newTestElement("Deformer", []*Property{newStringProperty("SubDeformer::Cluster Bone"), newStringProperty("Cluster")},
+				newTestElement("Indexes", []*Property{newIntArrayProperty(0, 1)}),
+				newTestElement("Weights", []*Property{newDoubleArrayProperty(1, 0.5)}),
+			),
+			newTestElement("GlobalSettings", nil,
+				newTestElement("Properties60", nil,
+					newProperty60("UnitScaleFactor", "double", newDoubleProperty(100)),
+				),
+			),
+		),
+		newTestElement("Connections", nil,
+			newLegacyConnect("OO", "Model::Cube", "Model::Scene"),
+			newLegacyConnect("OO", "Model::Bone", "Model::Scene"),
+			newLegacyConnect("OO", "Material::red", "Model::Cube"),
+			newLegacyConnect("OO", "Deformer::Skin Cube", "Model::Cube"),
+			newLegacyConnect("OO", "SubDeformer::Cluster Bone", "Deformer::Skin Cube"),
+			newLegacyConnect("OO", "Model::Bone", "SubDeformer::Cluster Bone"),
+			newLegacyConnect("OO", "Model::Missing", "Model::Scene"),
+		),
+		newTestElement("Takes", nil,
+			newTestElement("Current", []*Property{newStringProperty("Take 001")}),
+			newTestElement("Take", []*Property{newStringProperty("Take 001")},
+				newTestElement("LocalTime", []*Property{newLongProperty(0), newLongProperty(second)}),
+				newTestElement("Model", []*Property{newStringProperty("Model::Bone")},
+					newLegacyChannel("Transform",
+						newLegacyChannel("T",
+							newLegacyChannel("X",
+								newTestElement("Default", []*Property{newDoubleProperty(0)}),
+								newTestElement("Key", []*Property{
+									newLongProperty(0), newDoubleProperty(0), newStringProperty("L"),
+									newLongProperty(second), newDoubleProperty(10), newStringProperty("C"), newStringProperty("n"),
+								}),
+							),
+							newLegacyChannel("Y", newTestElement("Default", []*Property{newDoubleProperty(5)})),
+						),
+						newLegacyChannel("S"),
+					),
+				),
+			),
+		),
+	)
+}
+
+func TestLoadLegacyScene(t *testing.T) {
+	scene, err := newScene(context.Background(), newLegacyScene(), Header{Version: 6100}, LoadOptions{})
+	require.NoError(t, err)
+
+	require.Len(t, scene.Meshes, 1)
+	mesh := scene.Meshes[0]
+	assert.Equal(t, "Cube\x00\x01Model", mesh.Name())
+	require.NotNil(t, mesh.Geometry)
+	assert.Len(t, mesh.Geometry.Vertices, 3)
+	assert.Equal(t, 2.0, getLocalTranslation(mesh)[1])
+	require.Len(t, mesh.Materials, 1)
+	assert.Equal(t, "red\x00\x01Material", mesh.Materials[0].Name())
+	assert.Equal(t, ShadingLambert, mesh.Materials[0].ShadingModel)
+	assert.Equal(t, Color{1, 0, 0}, mesh.Materials[0].DiffuseColor)
+	assert.Equal(t, float32(100), scene.Settings.UnitScaleFactor)
+
+	// The skin moves from the model to its geometry
+	require.NotNil(t, mesh.Geometry.Skin)
+	require.Len(t, mesh.Geometry.Skin.Clusters, 1)
+	cluster := mesh.Geometry.Skin.Clusters[0]
+	require.NotNil(t, cluster.Link)
+	assert.Equal(t, "Bone\x00\x01Model", cluster.Link.Name())
+	assert.Equal(t, LIMB_NODE, cluster.Link.Type())
+	assert.Equal(t, []float64{1, 0.5}, cluster.Weights)
+
+	require.Len(t, scene.AnimationStacks, 1)
+	stack := scene.AnimationStacks[0]
+	assert.Equal(t, "Take 001\x00\x01AnimStack", stack.Name())
+	require.Len(t, stack.Layers, 1)
+	layer := stack.Layers[0]
+	require.Len(t, layer.CurveNodes, 2)
+	translation := layer.GetCurveNode(cluster.Link, BoneTranslate)
+	require.NotNil(t, translation)
+	x := translation.Curves[0].Curve
+	require.NotNil(t, x)
+	require.Len(t, x.Times, 2)
+	assert.InDelta(t, float64(time.Second), float64(x.Times[1]), float64(time.Millisecond))
+	assert.Equal(t, []float32{0, 10}, x.Values)
+	assertPointsInDelta(t, [3]float64{0, 5, 0}, translation.GetNodeLocalTransform(0))
+	scaling := layer.GetCurveNode(cluster.Link, BoneScale)
+	require.NotNil(t, scaling)
+	assertPointsInDelta(t, [3]float64{1, 1, 1}, scaling.GetNodeLocalTransform(0))
+
+	require.Len(t, scene.TakeInfos, 1)
+	assert.Equal(t, 1.0, scene.TakeInfos[0].localTimeTo)
+}
+
+func TestConvertLegacyKeepsModernFiles(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	assert.NotEmpty(t, scene.Meshes)
+	for _, mesh := range scene.Meshes {
+		assert.NotNil(t, mesh.Geometry)
+	}
+}
@@ -0,0 +1,79 @@
+package ofbx
+
+import "fmt"
+
+// Limits bounds what a file may make the loader allocate and compute, so that untrusted files can't
+// exhaust memory, the stack or the CPU. A zero field uses the one in DefaultLimits, and a negative field
+// has no limit.
+type Limits struct {
+	// MaxArrayLength is the most elements an array property may have
+	MaxArrayLength int
+	// MaxDecompressedBytes is the most bytes the compressed arrays of a file may inflate to, together
+	MaxDecompressedBytes int64
+	// MaxStringLength is the most bytes a string property may have
+	MaxStringLength int
+	// MaxDepth is the deepest elements may nest
+	MaxDepth int
+	// MaxObjects is the most objects a scene may have. It is checked as the file is read.
+	MaxObjects int
+	// MaxPolygonCorners is the most corners a polygon may have
+	MaxPolygonCorners int
+}
+
+// DefaultLimits are the limits used for the zero fields of Limits. They fit most real files; very
+// large scenes may need higher ones.
+var DefaultLimits = Limits{
+	MaxArrayLength:       1 << 24,
+	MaxDecompressedBytes: 1 << 28,
+	MaxStringLength:      1 << 24,
+	MaxDepth:             64,
+	MaxObjects:           1 << 20,
+	MaxPolygonCorners:    1 << 16,
+}
+
+// LimitError is returned when a file goes over one of its Limits
+type LimitError struct {
+	// Limit names the field of Limits that was exceeded
+	Limit string
+	Value int64
+	Max   int64
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %d exceeds the limit of %d", e.Limit, e.Value, e.Max)
+}
+
+// checkLimit returns a LimitError when value is over max, or over def when max is zero
+func checkLimit(name string, value, max, def int64) error {
+	if max == 0 {
+		max = def
+	}
+	if max > 0 && value > max {
+		return &LimitError{Limit: name, Value: value, Max: max}
+	}
+	return nil
+}
+
+func (l Limits) checkArrayLength(n int) error {
+	return checkLimit("MaxArrayLength", int64(n), int64(l.MaxArrayLength), int64(DefaultLimits.MaxArrayLength))
+}
+
+func (l Limits) checkDecompressedBytes(n int64) error {
+	return checkLimit("MaxDecompressedBytes", n, l.MaxDecompressedBytes, DefaultLimits.MaxDecompressedBytes)
+}
+
+func (l Limits) checkStringLength(n int) error {
+	return checkLimit("MaxStringLength", int64(n), int64(l.MaxStringLength), int64(DefaultLimits.MaxStringLength))
+}
+
+func (l Limits) checkDepth(n int) error {
+	return checkLimit("MaxDepth", int64(n), int64(l.MaxDepth), int64(DefaultLimits.MaxDepth))
+}
+
+func (l Limits) checkObjects(n int) error {
+	return checkLimit("MaxObjects", int64(n), int64(l.MaxObjects), int64(DefaultLimits.MaxObjects))
+}
+
+func (l Limits) checkPolygonCorners(n int) error {
+	return checkLimit("MaxPolygonCorners", int64(n), int64(l.MaxPolygonCorners), int64(DefaultLimits.MaxPolygonCorners))
+}
@@ -0,0 +1,81 @@
+package ofbx
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadLimits(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/cube.fbx")
+	require.NoError(t, err)
+
+	tests := []struct {
+		limit  string
+		limits Limits
+	}{
+		{"MaxArrayLength", Limits{MaxArrayLength: 2}},
+		{"MaxDecompressedBytes", Limits{MaxDecompressedBytes: 16}},
+		{"MaxStringLength", Limits{MaxStringLength: 1}},
+		{"MaxDepth", Limits{MaxDepth: 1}},
+		{"MaxObjects", Limits{MaxObjects: 1}},
+		{"MaxPolygonCorners", Limits{MaxPolygonCorners: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.limit, func(t *testing.T) {
+			_, err := LoadWithOptions(bytes.NewReader(data), LoadOptions{Limits: tt.limits})
+			var le *LimitError
+			require.True(t, errors.As(err, &le), "%v", err)
+			assert.Equal(t, tt.limit, le.Limit)
+			assert.Greater(t, le.Value, le.Max)
+			var pe *ParseError
+			assert.True(t, errors.As(err, &pe), "%v", err)
+		})
+	}
+
+	_, err = LoadWithOptions(bytes.NewReader(data), LoadOptions{Limits: Limits{MaxArrayLength: -1, MaxDepth: -1}})
+	assert.NoError(t, err)
+}
+
+func TestReadPropertyHugeLength(t *testing.T) {
+	newCursor := func(data []byte) *Cursor {
+		countReader := NewCountReader(bytes.NewReader(data))
+		return &Cursor{Reader: bufio.NewReader(countReader), cr: countReader}
+	}
+
+	// Lengths past the end of the file fail without allocating them
+	_, err := newCursor([]byte{'R', 0xff, 0xff, 0xff, 0x7f, 1, 2}).readProperty()
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+
+	_, err = newCursor([]byte{'S', 0xff, 0xff, 0xff, 0xff, 'a'}).readProperty()
+	var le *LimitError
+	require.True(t, errors.As(err, &le), "%v", err)
+	assert.Equal(t, "MaxStringLength", le.Limit)
+
+	_, err = newCursor([]byte{'d', 0xff, 0xff, 0xff, 0xff, 1, 0, 0, 0, 4, 0, 0, 0}).readProperty()
+	require.True(t, errors.As(err, &le), "%v", err)
+	assert.Equal(t, "MaxArrayLength", le.Limit)
+}
+
+func TestArrayCountBeyondData(t *testing.T) {
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	w.Write(make([]byte, 16))
+	w.Close()
+
+	// The count claims far more than the data inflates to
+	prop := &Property{Type: ArrayDOUBLE, Count: DefaultLimits.MaxArrayLength, Encoding: 1, value: NewDataView(buf.String())}
+	_, err := parseBinaryArrayFloat64(prop)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+
+	prop = &Property{Type: ArrayINT, Count: 5, value: NewDataView(string(make([]byte, 16)))}
+	_, err = parseBinaryArrayInt(prop)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF), "%v", err)
+}
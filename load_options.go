@@ -0,0 +1,78 @@
+package ofbx
+
+import (
+	"context"
+	"io"
+)
+
+// LoadOptions controls what LoadWithOptions parses and how. The zero value parses everything, like Load.
+type LoadOptions struct {
+	// NoGeometry leaves meshes without geometry. Clusters keep their bind pose but have no indices or weights.
+	NoGeometry bool
+	// NoAnimation skips animation stacks, layers, curves and take infos
+	NoAnimation bool
+	// NoMaterials skips materials along with their textures and videos
+	NoMaterials bool
+	// NoBakeGeometricTransform keeps the geometric transform of meshes out of their vertices
+	NoBakeGeometricTransform bool
+	// NoTriangulate leaves geometry as polygons: Geometry.Materials is not filled and cluster
+	// indices refer to control points instead of triangulated vertices
+	NoTriangulate bool
+	// DiscardElements drops RootElement and the property templates once the objects are built.
+	// Only the top-level tree is freed: each object keeps its own element subtree, which its
+	// property getters read from, so the objects' elements stay in memory as long as they do.
+	DiscardElements bool
+	// AxisSystem converts the scene to this axis system when set
+	AxisSystem AxisSystem
+	// MetersPerUnit converts the scene to this unit when set
+	MetersPerUnit float64
+	// Lenient skips or repairs what fails to parse instead of failing the load, and records each
+	// problem in Scene.Warnings
+	Lenient bool
+	// Progress is told how much of the file has been read, when it isn't nil
+	Progress ProgressFunc
+	// Limits bounds what the file may make the loader allocate. The zero value uses DefaultLimits.
+	Limits Limits
+}
+
+// LoadWithOptions loads a scene, parsing only what the options ask for
+func LoadWithOptions(r io.Reader, opts LoadOptions) (*Scene, error) {
+	return LoadContext(context.Background(), r, opts)
+}
+
+// LoadContext loads a scene like LoadWithOptions, and stops with the context's error once the
+// context is done. The size of the file is known to opts.Progress when r is an io.Seeker or has
+// a Size method, such as bytes.Reader.
+func LoadContext(ctx context.Context, r io.Reader, opts LoadOptions) (*Scene, error) {
+	root, header, err := tokenize(ctx, r, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newScene(ctx, root, header, opts)
+}
+
+// skipsElement reports whether objects of the given element ID are left out
+func (o LoadOptions) skipsElement(id string) bool {
+	switch id {
+	case "Geometry":
+		return o.NoGeometry
+	case "AnimationStack", "AnimationLayer", "AnimationCurve", "AnimationCurveNode":
+		return o.NoAnimation
+	case "Material", "Texture", "LayeredTexture", "Video":
+		return o.NoMaterials
+	}
+	return false
+}
+
+// convert applies the target axis system and units
+func (o LoadOptions) convert(s *Scene) error {
+	if o.AxisSystem != (AxisSystem{}) {
+		if err := s.ConvertAxisSystem(o.AxisSystem); err != nil {
+			return err
+		}
+	}
+	if o.MetersPerUnit != 0 {
+		return s.ConvertUnits(o.MetersPerUnit)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package ofbx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadWithOptionsSkips(t *testing.T) {
+	scene := loadTestSceneWithOptions(t, "testdata/FBXcs2.fbx", LoadOptions{NoGeometry: true, NoAnimation: true, NoMaterials: true})
+	require.NotEmpty(t, scene.Meshes)
+	for _, mesh := range scene.Meshes {
+		assert.Nil(t, mesh.Geometry)
+		assert.Empty(t, mesh.Materials)
+	}
+	for _, obj := range scene.ObjectMap {
+		_, ok := obj.(*Geometry)
+		assert.False(t, ok)
+	}
+	assert.Empty(t, scene.AnimationStacks)
+	assert.Empty(t, scene.TakeInfos)
+	assert.Empty(t, scene.Videos)
+	assert.NotNil(t, scene.RootElement)
+
+	full := loadTestScene(t, "testdata/FBXcs2.fbx")
+	assert.Equal(t, len(full.Meshes), len(scene.Meshes))
+	assert.NotEmpty(t, full.TakeInfos)
+	assert.NotEmpty(t, full.Videos)
+}
+
+func TestLoadWithOptionsNoBakeGeometricTransform(t *testing.T) {
+	baked := loadTestScene(t, "testdata/jyj.FBX")
+	scene := loadTestSceneWithOptions(t, "testdata/jyj.FBX", LoadOptions{NoBakeGeometricTransform: true})
+	require.Equal(t, len(baked.Meshes), len(scene.Meshes))
+	for i, mesh := range scene.Meshes {
+		mtx := mesh.getGeometricMatrix()
+		for j, v := range mesh.Geometry.Vertices {
+			assertPointsInDelta(t, baked.Meshes[i].Geometry.Vertices[j], mtx.MulPosition(v))
+		}
+	}
+}
+
+func TestLoadWithOptionsNoTriangulate(t *testing.T) {
+	scene := loadTestSceneWithOptions(t, "testdata/cube.fbx", LoadOptions{NoTriangulate: true, DiscardElements: true})
+	assert.Nil(t, scene.RootElement)
+	require.NotEmpty(t, scene.Meshes)
+	// Objects still read their properties from their own elements
+	assert.NotNil(t, scene.Meshes[0].Element())
+	assert.NotPanics(t, func() { scene.Meshes[0].GetGlobalMatrix() })
+	geom := scene.Meshes[0].Geometry
+	require.NotNil(t, geom)
+	assert.Empty(t, geom.GetOldVerts())
+	assert.Empty(t, geom.Materials)
+	for i := range geom.Vertices {
+		assert.Equal(t, i, geom.newVerts[i].index)
+		assert.Nil(t, geom.newVerts[i].next)
+	}
+	// Triangle meshes are still built from the polygons
+	assert.NotEmpty(t, geom.BuildTriangleMesh(TriangleMeshOptions{}).Indices)
+}
+
+func TestLoadWithOptionsConvert(t *testing.T) {
+	scene := loadTestSceneWithOptions(t, "testdata/cube.fbx", LoadOptions{AxisSystem: AxisSystemZUp, MetersPerUnit: 1})
+	assert.Equal(t, AxisSystemZUp, scene.Settings.AxisSystem())
+	assert.Equal(t, 1.0, scene.Settings.MetersPerUnit())
+
+	f, err := os.Open("testdata/cube.fbx")
+	require.NoError(t, err)
+	defer f.Close()
+	_, err = LoadWithOptions(f, LoadOptions{AxisSystem: AxisSystem{Up: AxisY, Front: AxisY, Coord: AxisX}})
+	assert.Error(t, err)
+}
+
+func TestLoadContextProgress(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/cube.fbx")
+	require.NoError(t, err)
+	var last, total int64
+	calls := 0
+	_, err = LoadContext(context.Background(), bytes.NewReader(data), LoadOptions{Progress: func(read, size int64) {
+		assert.GreaterOrEqual(t, read, last)
+		last, total = read, size
+		calls++
+	}})
+	require.NoError(t, err)
+	assert.Greater(t, calls, 1)
+	// Reports come a percent of the file apart, not for every element
+	assert.LessOrEqual(t, calls, 101)
+	assert.Equal(t, int64(len(data)), total)
+	assert.LessOrEqual(t, last, total)
+	assert.Greater(t, last, total/2)
+}
+
+func TestLoadContextCancel(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/FBXcs2.fbx")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = LoadContext(ctx, bytes.NewReader(data), LoadOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Cancelling half way through stops reading
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var stopped int64
+	_, err = LoadContext(ctx, bytes.NewReader(data), LoadOptions{Progress: func(read, total int64) {
+		if stopped == 0 && read > total/2 {
+			stopped = read
+			cancel()
+		}
+	}})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotZero(t, stopped)
+
+	ok, err := parseObjects(ctx, newTestRoot([]*Element{newTextureElement(1)}), &Scene{ObjectMap: make(map[uint64]Obj)})
+	assert.False(t, ok)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestReaderSize(t *testing.T) {
+	f, err := os.Open("testdata/cube.fbx")
+	require.NoError(t, err)
+	defer f.Close()
+	info, err := f.Stat()
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), readerSize(f))
+
+	_, err = f.Seek(10, io.SeekStart)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size()-10, readerSize(f))
+	pos, err := f.Seek(0, io.SeekCurrent)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), pos)
+
+	assert.Equal(t, int64(-1), readerSize(io.LimitReader(f, 5)))
+
+	// Size counts all of these readers, including what was already read
+	br := bytes.NewReader(make([]byte, 100))
+	_, err = br.Seek(30, io.SeekStart)
+	require.NoError(t, err)
+	assert.Equal(t, int64(70), readerSize(br))
+	sr := io.NewSectionReader(br, 20, 50)
+	_, err = sr.Seek(5, io.SeekStart)
+	require.NoError(t, err)
+	assert.Equal(t, int64(45), readerSize(sr))
+}
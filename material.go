@@ -1,32 +1,127 @@
 package ofbx
 
-import "fmt"
+import (
+	"fmt"
+	"math"
+)
+
+// ShadingModel is the lighting model a material was authored for
+type ShadingModel int
+
+// ShadingModel options
+const (
+	// ShadingUnknown is used by shaders FBX has no surface class for, such as PBR ones
+	ShadingUnknown ShadingModel = iota
+	ShadingLambert ShadingModel = iota
+	ShadingPhong   ShadingModel = iota
+)
+
+func (s ShadingModel) String() string {
+	switch s {
+	case ShadingLambert:
+		return "lambert"
+	case ShadingPhong:
+		return "phong"
+	}
+	return "unknown"
+}
 
 // Material stores texture pointers and how to apply them
 type Material struct {
 	Object
-	EmissiveColor     Color
-	EmissiveFactor    float64
-	AmbientColor      Color
-	DiffuseColor      Color
-	DiffuseFactor     float64
-	TransparentColor  Color
-	SpecularColor     Color
-	SpecularFactor    float64
-	Shininess         float64
-	ShininessExponent float64
-	ReflectionColor   Color
-	ReflectionFactor  float64
-	Textures          [TextureCOUNT]*Texture
+	ShadingModel ShadingModel
+	MultiLayer   bool
+
+	EmissiveColor      Color
+	EmissiveFactor     float64
+	AmbientColor       Color
+	AmbientFactor      float64
+	DiffuseColor       Color
+	DiffuseFactor      float64
+	TransparentColor   Color
+	TransparencyFactor float64
+	// Opacity is the legacy opacity some exporters, such as 3ds Max, write instead of TransparentColor
+	Opacity                  float64
+	Bump                     Color
+	BumpFactor               float64
+	NormalMap                Color
+	DisplacementColor        Color
+	DisplacementFactor       float64
+	VectorDisplacementColor  Color
+	VectorDisplacementFactor float64
+	SpecularColor            Color
+	SpecularFactor           float64
+	Shininess                float64
+	ShininessExponent        float64
+	ReflectionColor          Color
+	ReflectionFactor         float64
+	// Textures holds the textures connected to each material property, such as "DiffuseColor", in connection order
+	Textures map[string][]*Texture
+	// LayeredTextures holds the layered textures connected to each material property, in connection order
+	LayeredTextures map[string][]*LayeredTexture
 }
 
 // NewMaterial makes a stub Material
 func NewMaterial(scene *Scene, element *Element) *Material {
 	m := &Material{}
 	m.Object = *NewObject(scene, element)
+	m.Textures = make(map[string][]*Texture)
+	m.LayeredTextures = make(map[string][]*LayeredTexture)
 	return m
 }
 
+// Transparency returns how transparent the material is, from 0 for opaque to 1, taking the
+// legacy Opacity into account when TransparentColor and TransparencyFactor are not set
+func (m *Material) Transparency() float64 {
+	t := m.TransparencyFactor * (float64(m.TransparentColor.R) + float64(m.TransparentColor.G) + float64(m.TransparentColor.B)) / 3
+	if t == 0 && m.Opacity < 1 {
+		t = 1 - m.Opacity
+	}
+	return math.Min(math.Max(t, 0), 1)
+}
+
+// GetTexture returns the first texture connected to one of the properties of the texture type, or nil
+func (m *Material) GetTexture(t TextureType) *Texture {
+	if t < 0 || t >= TextureCOUNT {
+		return nil
+	}
+	for _, property := range textureProperties[t] {
+		if tex := m.PropertyTexture(property); tex != nil {
+			return tex
+		}
+	}
+	return nil
+}
+
+// PropertyTexture returns the first texture connected to the named property, or the base layer of
+// the first layered texture connected to it, or nil
+func (m *Material) PropertyTexture(property string) *Texture {
+	if textures := m.Textures[property]; len(textures) > 0 {
+		return textures[0]
+	}
+	for _, lt := range m.LayeredTextures[property] {
+		if base := lt.BaseTexture(); base != nil {
+			return base
+		}
+	}
+	return nil
+}
+
+// addTexture connects a texture to a material property, after any already connected to it
+func (m *Material) addTexture(property string, t *Texture) {
+	if m.Textures == nil {
+		m.Textures = make(map[string][]*Texture)
+	}
+	m.Textures[property] = append(m.Textures[property], t)
+}
+
+func (m *Material) addLayeredTexture(property string, lt *LayeredTexture) {
+	if m.LayeredTextures == nil {
+		m.LayeredTextures = make(map[string][]*LayeredTexture)
+	}
+	m.LayeredTextures[property] = append(m.LayeredTextures[property], lt)
+}
+
 // Type returns MATERIAl
 func (m *Material) Type() Type {
 	return MATERIAL
@@ -38,23 +133,26 @@ func (m *Material) String() string {
 
 func (m *Material) stringPrefix(prefix string) string {
 	s := prefix + "Material: " + "\n"
+	s += prefix + "ShadingModel: " + m.ShadingModel.String() + "\n"
 	s += prefix + "EmissiveColor" + m.EmissiveColor.String() + "\n"
 	s += prefix + fmt.Sprintf("EmissiveFactor: %f", m.EmissiveFactor) + "\n"
 	s += prefix + "AmbientColor" + m.AmbientColor.String() + "\n"
 	s += prefix + "DiffuseColor" + m.DiffuseColor.String() + "\n"
 	s += prefix + fmt.Sprintf("DiffuseFactor: %f", m.DiffuseFactor) + "\n"
 	s += prefix + "TransparentColor" + m.TransparentColor.String() + "\n"
+	s += prefix + fmt.Sprintf("TransparencyFactor: %f", m.TransparencyFactor) + "\n"
+	s += prefix + fmt.Sprintf("Opacity: %f", m.Opacity) + "\n"
 	s += prefix + "SpecularColor" + m.SpecularColor.String() + "\n"
 	s += prefix + fmt.Sprintf("SpecularFactor: %f", m.SpecularFactor) + "\n"
 	s += prefix + fmt.Sprintf("Shininess: %f", m.Shininess) + "\n"
 	s += prefix + fmt.Sprintf("ShininessExponent: %f", m.ShininessExponent) + "\n"
 	s += prefix + "ReflectionColor" + m.ReflectionColor.String() + "\n"
 	s += prefix + fmt.Sprintf("ReflectionFactor: %f", m.ReflectionFactor) + "\n"
-	if m.Textures[DIFFUSE] != nil {
-		s += "Diffuse Texture: " + m.Textures[DIFFUSE].String() + "\n"
+	if tex := m.GetTexture(DIFFUSE); tex != nil {
+		s += "Diffuse Texture: " + tex.String() + "\n"
 	}
-	if m.Textures[NORMAL] != nil {
-		s += "Normal Texture: " + m.Textures[NORMAL].String() + "\n"
+	if tex := m.GetTexture(NORMAL); tex != nil {
+		s += "Normal Texture: " + tex.String() + "\n"
 	}
 	return s
 }
@@ -61,18 +61,18 @@ func TestMaterialWithTextures(t *testing.T) {
 	// Add diffuse texture
 	diffuseElement := &Element{ID: NewDataView("diffuse_texture")}
 	diffuseTexture := &Texture{Object: *NewObject(scene, diffuseElement)}
-	material.Textures[DIFFUSE] = diffuseTexture
+	material.addTexture("DiffuseColor", diffuseTexture)
 	
 	// Add normal texture
 	normalElement := &Element{ID: NewDataView("normal_texture")}
 	normalTexture := &Texture{Object: *NewObject(scene, normalElement)}
-	material.Textures[NORMAL] = normalTexture
+	material.addTexture("NormalMap", normalTexture)
 	
 	// Test that textures are set correctly
-	if material.Textures[DIFFUSE] != diffuseTexture {
+	if material.GetTexture(DIFFUSE) != diffuseTexture {
 		t.Error("Diffuse texture not set correctly")
 	}
-	if material.Textures[NORMAL] != normalTexture {
+	if material.GetTexture(NORMAL) != normalTexture {
 		t.Error("Normal texture not set correctly")
 	}
 	
@@ -146,3 +146,46 @@ func TestMaterialEmpty(t *testing.T) {
 		t.Error("String should contain basic material information")
 	}
 }
+
+func TestMaterialTextureSlots(t *testing.T) {
+	scene := &Scene{}
+	material := NewMaterial(scene, &Element{ID: NewDataView("test_material")})
+	first := NewTexture(scene, &Element{ID: NewDataView("first")})
+	second := NewTexture(scene, &Element{ID: NewDataView("second")})
+	specular := NewTexture(scene, &Element{ID: NewDataView("specular")})
+
+	material.addTexture("DiffuseColor", first)
+	material.addTexture("DiffuseColor", second)
+	material.addTexture("SpecularFactor", specular)
+
+	if len(material.Textures["DiffuseColor"]) != 2 || material.Textures["DiffuseColor"][1] != second {
+		t.Errorf("Expected both diffuse textures in connection order, got %v", material.Textures["DiffuseColor"])
+	}
+	if material.GetTexture(DIFFUSE) != first {
+		t.Error("Expected the first diffuse texture")
+	}
+	if material.GetTexture(SPECULAR) != specular {
+		t.Error("Expected the specular texture through its fallback property")
+	}
+	if material.GetTexture(BUMP) != nil || material.GetTexture(TextureCOUNT) != nil {
+		t.Error("Expected no texture for unconnected or invalid types")
+	}
+	if SHININESS.Property() != "ShininessExponent" {
+		t.Errorf("Unexpected property %q", SHININESS.Property())
+	}
+}
+
+func TestMaterialTexturesFromScene(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	found := 0
+	for _, mesh := range scene.Meshes {
+		for _, material := range mesh.Materials {
+			if material.GetTexture(DIFFUSE) != nil {
+				found++
+			}
+		}
+	}
+	if found == 0 {
+		t.Error("Expected diffuse textures in FBXcs2.fbx")
+	}
+}
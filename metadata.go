@@ -0,0 +1,234 @@
+package ofbx
+
+import (
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Metadata describes the file and the application that wrote it
+type Metadata struct {
+	// Version is the FBX version of the file, such as 7400
+	Version uint32
+	// Creator is the library that wrote the file, such as "FBX SDK/FBX Plugins version 2020.0"
+	Creator string
+	// CreationTime is the wall-clock time the file was written at. Files don't record
+	// the writer's time zone, so the time is labelled UTC even though it usually isn't.
+	CreationTime time.Time
+	FileID       []byte
+
+	Title, Subject, Author, Keywords, Revision, Comment string
+	// DocumentURL is the path the file was written to
+	DocumentURL    string
+	SrcDocumentURL string
+	// Original is the application that created the scene, LastSaved the one that last wrote it
+	Original, LastSaved ApplicationInfo
+	// Thumbnail is the preview image stored with the scene, or nil
+	Thumbnail *Thumbnail
+}
+
+// ApplicationInfo identifies an application that wrote a scene
+type ApplicationInfo struct {
+	Vendor, Name, Version string
+	// DateTime is when the application saved the scene, in UTC
+	DateTime time.Time
+	FileName string
+	// ActiveProject and NativeFile are only written by some applications, such as 3ds Max
+	ActiveProject, NativeFile string
+}
+
+// Thumbnail is the raw preview image stored in a scene's SceneInfo
+type Thumbnail struct {
+	// Format is 0 for RGB and 1 for RGBA
+	Format int
+	// Size is the width and height in pixels, 64 or 128
+	Size int
+	// Data holds the pixels, row by row
+	Data []byte
+}
+
+// fbxDateTimeLayout is the layout of the DateTime_GMT properties
+const fbxDateTimeLayout = "02/01/2006 15:04:05.000"
+
+func parseMetadata(root *Element, header Header) Metadata {
+	md := Metadata{Version: header.Version}
+	if prop := findSingleChildProperty(root, "Creator"); prop != nil {
+		md.Creator = prop.value.String()
+	}
+	if prop := findSingleChildProperty(root, "FileId"); prop != nil && prop.Type == RAWSTRING {
+		md.FileID = []byte(prop.value.String())
+	}
+	if prop := findSingleChildProperty(root, "CreationTime"); prop != nil {
+		// Such as "2021-06-09 14:38:42:389", the milliseconds are left to CreationTimeStamp
+		if s := prop.value.String(); len(s) >= 19 {
+			if t, err := time.Parse("2006-01-02 15:04:05", s[:19]); err == nil {
+				md.CreationTime = t
+			}
+		}
+	}
+
+	headers := findChildren(root, "FBXHeaderExtension")
+	if len(headers) == 0 {
+		return md
+	}
+	ext := headers[0]
+	if md.Version == 0 {
+		if prop := findSingleChildProperty(ext, "FBXVersion"); prop != nil {
+			md.Version = uint32(prop.toInt64())
+		}
+	}
+	if md.Creator == "" {
+		if prop := findSingleChildProperty(ext, "Creator"); prop != nil {
+			md.Creator = prop.value.String()
+		}
+	}
+	if stamps := findChildren(ext, "CreationTimeStamp"); len(stamps) != 0 {
+		stamp := stamps[0]
+		field := func(name string) int {
+			if prop := findSingleChildProperty(stamp, name); prop != nil {
+				return int(prop.toInt64())
+			}
+			return 0
+		}
+		if year := field("Year"); year != 0 {
+			md.CreationTime = time.Date(year, time.Month(field("Month")), field("Day"),
+				field("Hour"), field("Minute"), field("Second"), field("Millisecond")*int(time.Millisecond), time.UTC)
+		}
+	}
+
+	infos := findChildren(ext, "SceneInfo")
+	if len(infos) == 0 {
+		return md
+	}
+	info := infos[0]
+	if metas := findChildren(info, "MetaData"); len(metas) != 0 {
+		for _, field := range []struct {
+			name string
+			dst  *string
+		}{
+			{"Title", &md.Title},
+			{"Subject", &md.Subject},
+			{"Author", &md.Author},
+			{"Keywords", &md.Keywords},
+			{"Revision", &md.Revision},
+			{"Comment", &md.Comment},
+		} {
+			if prop := findSingleChildProperty(metas[0], field.name); prop != nil {
+				*field.dst = prop.value.String()
+			}
+		}
+	}
+	if thumbs := findChildren(info, "Thumbnail"); len(thumbs) != 0 {
+		md.Thumbnail = parseThumbnail(thumbs[0])
+	}
+
+	props := findChildren(info, "Properties70")
+	if len(props) == 0 {
+		return md
+	}
+	for _, node := range props[0].Children {
+		p, value := node.getProperty(0), node.getProperty(4)
+		if p == nil || value == nil {
+			continue
+		}
+		name := p.value.String()
+		app := &md.Original
+		if strings.HasPrefix(name, "LastSaved|") {
+			app = &md.LastSaved
+		}
+		if i := strings.IndexByte(name, '|'); i >= 0 {
+			name = name[i+1:]
+		}
+		switch name {
+		case "DocumentUrl":
+			md.DocumentURL = value.value.String()
+		case "SrcDocumentUrl":
+			md.SrcDocumentURL = value.value.String()
+		case "ApplicationVendor":
+			app.Vendor = value.value.String()
+		case "ApplicationName":
+			app.Name = value.value.String()
+		case "ApplicationVersion":
+			app.Version = value.value.String()
+		case "DateTime_GMT":
+			if t, err := time.Parse(fbxDateTimeLayout, value.value.String()); err == nil {
+				app.DateTime = t
+			}
+		case "FileName":
+			app.FileName = value.value.String()
+		case "ApplicationActiveProject":
+			app.ActiveProject = value.value.String()
+		case "ApplicationNativeFile":
+			app.NativeFile = value.value.String()
+		}
+	}
+	return md
+}
+
+func parseThumbnail(element *Element) *Thumbnail {
+	thumb := &Thumbnail{}
+	if prop := findSingleChildProperty(element, "Format"); prop != nil {
+		thumb.Format = int(prop.toInt64())
+	}
+	if prop := findSingleChildProperty(element, "Size"); prop != nil {
+		thumb.Size = int(prop.toInt64())
+	}
+	prop := findSingleChildProperty(element, "ImageData")
+	if prop == nil {
+		return thumb
+	}
+	switch prop.Type {
+	case RAWSTRING:
+		thumb.Data = []byte(prop.value.String())
+	case ArrayBYTE, ArrayBOOL:
+		thumb.Data, _ = parseBinaryArrayByte(prop)
+	case ArrayINT, ArrayLONG:
+		ints, err := parseBinaryArrayInt(prop)
+		if err == nil {
+			thumb.Data = make([]byte, len(ints))
+			for i, v := range ints {
+				thumb.Data[i] = byte(v)
+			}
+		}
+	}
+	return thumb
+}
+
+// Image returns the thumbnail's pixels as an image
+func (t *Thumbnail) Image() (image.Image, error) {
+	channels := 3
+	if t.Format == 1 {
+		channels = 4
+	} else if t.Format != 0 {
+		return nil, errors.Errorf("Invalid thumbnail: unknown format %d", t.Format)
+	}
+	size := t.Size
+	if size == 0 {
+		// Files without a Size have as many pixels as the data holds
+		switch len(t.Data) / channels {
+		case 64 * 64:
+			size = 64
+		case 128 * 128:
+			size = 128
+		}
+	}
+	if size != 64 && size != 128 {
+		return nil, errors.Errorf("Invalid thumbnail: unknown size %d", t.Size)
+	}
+	if len(t.Data) < size*size*channels {
+		return nil, errors.Errorf("Invalid thumbnail: %d bytes for %dx%d pixels", len(t.Data), size, size)
+	}
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	for i := 0; i < size*size; i++ {
+		px := t.Data[i*channels:]
+		c := color.NRGBA{px[0], px[1], px[2], 255}
+		if channels == 4 {
+			c.A = px[3]
+		}
+		img.SetNRGBA(i%size, i/size, c)
+	}
+	return img, nil
+}
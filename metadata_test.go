@@ -0,0 +1,99 @@
+package ofbx
+
+import (
+	"image"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataFromFile(t *testing.T) {
+	md := loadTestScene(t, "testdata/FBXcs2.fbx").Metadata
+	assert.Equal(t, uint32(7700), md.Version)
+	assert.Equal(t, "FBX SDK/FBX Plugins version 2020.0 build=6dea16853", md.Creator)
+	assert.Equal(t, time.Date(2021, 6, 9, 14, 38, 42, 389*int(time.Millisecond), time.UTC), md.CreationTime)
+	assert.NotEmpty(t, md.FileID)
+	assert.Equal(t, `D:\20210526_Q_City\scenes\FBX\FBXcs2.fbx`, md.DocumentURL)
+	assert.Equal(t, "Autodesk", md.Original.Vendor)
+	assert.Equal(t, "Maya", md.Original.Name)
+	assert.Equal(t, "202000", md.Original.Version)
+	assert.Equal(t, "Maya", md.LastSaved.Name)
+	assert.Equal(t, time.Date(2021, 6, 9, 6, 38, 42, 388*int(time.Millisecond), time.UTC), md.LastSaved.DateTime)
+	assert.Nil(t, md.Thumbnail)
+
+	md = loadTestScene(t, "testdata/jyj.FBX").Metadata
+	assert.Equal(t, "3ds Max", md.Original.Name)
+	assert.Equal(t, "2020", md.LastSaved.Version)
+	assert.Equal(t, `G:\80mm厚板卷板机.max`, md.Original.NativeFile)
+}
+
+func TestParseMetadata(t *testing.T) {
+	pixels := make([]int32, 64*64*4)
+	pixels[4*65], pixels[4*65+3] = 200, 255
+	thumbData := newIntArrayProperty(pixels...)
+	root := newTestElement("", nil,
+		newTestElement("FBXHeaderExtension", nil,
+			newTestElement("FBXVersion", []*Property{newIntegerProperty(7500)}),
+			newTestElement("Creator", []*Property{newStringProperty("exporter")}),
+			newTestElement("SceneInfo", []*Property{newStringProperty("GlobalInfo\x00\x01SceneInfo"), newStringProperty("UserData")},
+				newTestElement("MetaData", nil,
+					newTestElement("Title", []*Property{newStringProperty("city")}),
+					newTestElement("Author", []*Property{newStringProperty("someone")}),
+				),
+				newTestElement("Thumbnail", nil,
+					newTestElement("Version", []*Property{newIntegerProperty(100)}),
+					newTestElement("Format", []*Property{newIntegerProperty(1)}),
+					newTestElement("Size", []*Property{newIntegerProperty(64)}),
+					newTestElement("ImageFormat", []*Property{newIntegerProperty(0)}),
+					newTestElement("ImageData", []*Property{thumbData}),
+				),
+			),
+		),
+	)
+	md := parseMetadata(root, Header{})
+	assert.Equal(t, uint32(7500), md.Version)
+	assert.Equal(t, "exporter", md.Creator)
+	assert.Equal(t, "city", md.Title)
+	assert.Equal(t, "someone", md.Author)
+	assert.True(t, md.CreationTime.IsZero())
+	require.NotNil(t, md.Thumbnail)
+	assert.Equal(t, 1, md.Thumbnail.Format)
+	assert.Equal(t, 64, md.Thumbnail.Size)
+	assert.Len(t, md.Thumbnail.Data, 64*64*4)
+	img, err := md.Thumbnail.Image()
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), img.Bounds())
+	assert.Equal(t, color.NRGBA{200, 0, 0, 255}, img.At(1, 1))
+
+	assert.Equal(t, uint32(7100), parseMetadata(newTestElement("", nil), Header{Version: 7100}).Version)
+}
+
+func TestThumbnailImage(t *testing.T) {
+	data := make([]byte, 64*64*3)
+	copy(data[3*65:], []byte{10, 20, 30})
+	img, err := (&Thumbnail{Format: 0, Size: 64, Data: data}).Image()
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 64, 64), img.Bounds())
+	assert.Equal(t, color.NRGBA{10, 20, 30, 255}, img.At(1, 1))
+
+	data = make([]byte, 128*128*4)
+	data[3] = 7
+	img, err = (&Thumbnail{Format: 1, Size: 128, Data: data}).Image()
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{0, 0, 0, 7}, img.At(0, 0))
+
+	// Without a Size the data decides
+	img, err = (&Thumbnail{Format: 1, Data: data}).Image()
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 128, 128), img.Bounds())
+
+	_, err = (&Thumbnail{Format: 1, Size: 64, Data: make([]byte, 64*64*3)}).Image()
+	assert.Error(t, err)
+	_, err = (&Thumbnail{Format: 0, Size: 1, Data: data}).Image()
+	assert.Error(t, err)
+	_, err = (&Thumbnail{Format: 2}).Image()
+	assert.Error(t, err)
+}
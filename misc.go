@@ -20,7 +20,7 @@ func resolveVec3Property(object Obj, name string, defaultVal floatgeom.Point3) f
 	if element == nil {
 		return defaultVal
 	}
-	if len(element.Properties) < 6 {
+	if len(element.Properties) < 7 {
 		return defaultVal
 	}
 
@@ -40,7 +40,7 @@ func splatVec2(mapping VertexDataMapping, data []floatgeom.Point2, indices []int
 		} else {
 			out = make([]floatgeom.Point2, len(indices))
 			for i := 0; i < len(indices); i++ {
-				if indices[i] < len(data) {
+				if indices[i] >= 0 && indices[i] < len(data) {
 					out[i] = data[indices[i]]
 				} else {
 					out[i] = floatgeom.Point2{}
@@ -70,14 +70,14 @@ func splatVec2(mapping VertexDataMapping, data []floatgeom.Point2, indices []int
 
 func splatVec3(mapping VertexDataMapping, data []floatgeom.Point3, indices []int, origIndices []int) (out []floatgeom.Point3) {
 	switch mapping {
-	case ByPolygonVertex:
+	case ByPolygonVertex, ByPolygon:
 		if len(indices) == 0 {
 			out = make([]floatgeom.Point3, len(data))
 			copy(out, data)
 		} else {
 			out = make([]floatgeom.Point3, len(indices))
 			for i := 0; i < len(indices); i++ {
-				if indices[i] < len(data) {
+				if indices[i] >= 0 && indices[i] < len(data) {
 					out[i] = data[indices[i]]
 				} else {
 					out[i] = floatgeom.Point3{}
@@ -101,22 +101,20 @@ func splatVec3(mapping VertexDataMapping, data []floatgeom.Point3, indices []int
 				out[i] = floatgeom.Point3{}
 			}
 		}
-	default:
-		panic("oh no")
 	}
 	return out
 }
 
 func splatVec4(mapping VertexDataMapping, data []floatgeom.Point4, indices []int, origIndices []int) (out []floatgeom.Point4) {
 	switch mapping {
-	case ByPolygonVertex:
+	case ByPolygonVertex, ByPolygon:
 		if len(indices) == 0 {
 			out = make([]floatgeom.Point4, len(data))
 			copy(out, data)
 		} else {
 			out = make([]floatgeom.Point4, len(indices))
 			for i := 0; i < len(indices); i++ {
-				if indices[i] < len(data) {
+				if indices[i] >= 0 && indices[i] < len(data) {
 					out[i] = data[indices[i]]
 				} else {
 					out[i] = floatgeom.Point4{}
@@ -140,8 +138,6 @@ func splatVec4(mapping VertexDataMapping, data []floatgeom.Point4, indices []int
 				out[i] = floatgeom.Point4{}
 			}
 		}
-	default:
-		panic("oh no")
 	}
 	return out
 }
@@ -154,7 +150,7 @@ func RemapVec2(out *[]floatgeom.Point2, m []int) {
 	old := make([]floatgeom.Point2, len(*out))
 	copy(old, *out)
 	for i := 0; i < len(m); i++ {
-		if m[i] < len(old) {
+		if m[i] >= 0 && m[i] < len(old) {
 			*out = append(*out, old[m[i]])
 		} else {
 			*out = append(*out, floatgeom.Point2{})
@@ -170,7 +166,7 @@ func RemapVec3(out *[]floatgeom.Point3, m []int) {
 	old := make([]floatgeom.Point3, len(*out))
 	copy(old, *out)
 	for i := 0; i < len(m); i++ {
-		if m[i] < len(old) {
+		if m[i] >= 0 && m[i] < len(old) {
 			*out = append(*out, old[m[i]])
 		} else {
 			*out = append(*out, floatgeom.Point3{})
@@ -186,10 +182,203 @@ func RemapVec4(out *[]floatgeom.Point4, m []int) {
 	old := make([]floatgeom.Point4, len(*out))
 	copy(old, *out)
 	for i := 0; i < len(m); i++ {
-		if m[i] < len(old) {
+		if m[i] >= 0 && m[i] < len(old) {
 			*out = append(*out, old[m[i]])
 		} else {
 			*out = append(*out, floatgeom.Point4{})
 		}
 	}
 }
+
+func resolveStringProperty(object Obj, name string, defaultVal string) string {
+	element := resolveProperty(object, name)
+	if element == nil || element.getProperty(4) == nil {
+		return defaultVal
+	}
+	return element.getProperty(4).value.String()
+}
+
+func resolveDoubleProperty(object Obj, name string, defaultVal float64) float64 {
+	element := resolveProperty(object, name)
+	if element == nil {
+		return defaultVal
+	}
+	x := element.getProperty(4)
+	if x == nil {
+		return defaultVal
+	}
+	return x.value.toDouble()
+}
+
+// resolveNumberProperty reads a property holding any numeric type, reporting whether it was present
+func resolveNumberProperty(object Obj, name string) (float64, bool) {
+	element := resolveProperty(object, name)
+	if element == nil || element.getProperty(4) == nil {
+		return 0, false
+	}
+	return element.getProperty(4).toFloat64(), true
+}
+
+// resolveColorProperty reads the RGB of a color property, ignoring any alpha, reporting whether it was present
+func resolveColorProperty(object Obj, name string) (Color, bool) {
+	element := resolveProperty(object, name)
+	if element == nil || len(element.Properties) < 7 {
+		return Color{}, false
+	}
+	return Color{
+		R: float32(element.getProperty(4).toFloat64()),
+		G: float32(element.getProperty(5).toFloat64()),
+		B: float32(element.getProperty(6).toFloat64()),
+	}, true
+}
+
+// setVec3Property overwrites an existing vector property, reporting whether it was present
+func setVec3Property(object Obj, name string, v floatgeom.Point3) bool {
+	element := resolveProperty(object, name)
+	if element == nil || len(element.Properties) < 7 {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		element.Properties[4+i] = newDoubleProperty(v[i])
+	}
+	return true
+}
+
+// setDoubleProperty overwrites an existing number property, reporting whether it was present
+func setDoubleProperty(object Obj, name string, f float64) bool {
+	element := resolveProperty(object, name)
+	if element == nil || len(element.Properties) < 5 {
+		return false
+	}
+	element.Properties[4] = newDoubleProperty(f)
+	return true
+}
+
+// setEnumProperty overwrites an enum property, adding it when the object does not define it
+func setEnumProperty(object Obj, name string, v int) {
+	element := resolveProperty(object, name)
+	if element == nil || len(element.Properties) < 5 {
+		addProperty70(object, name, "enum", "", "", newIntegerProperty(int32(v)))
+		return
+	}
+	element.Properties[4] = newIntegerProperty(int32(v))
+}
+
+// reorderVec2 returns data[remap[i]] for each i when data holds one value per remapped entry,
+// and data unchanged otherwise
+func reorderVec2(data []floatgeom.Point2, remap []int) []floatgeom.Point2 {
+	if len(data) != len(remap) {
+		return data
+	}
+	out := make([]floatgeom.Point2, len(data))
+	for i, j := range remap {
+		out[i] = data[j]
+	}
+	return out
+}
+
+func reorderVec3(data []floatgeom.Point3, remap []int) []floatgeom.Point3 {
+	if len(data) != len(remap) {
+		return data
+	}
+	out := make([]floatgeom.Point3, len(data))
+	for i, j := range remap {
+		out[i] = data[j]
+	}
+	return out
+}
+
+func reorderFloat64(data []float64, remap []int) []float64 {
+	if len(data) != len(remap) {
+		return data
+	}
+	out := make([]float64, len(data))
+	for i, j := range remap {
+		out[i] = data[j]
+	}
+	return out
+}
+
+func reorderVec4(data []floatgeom.Point4, remap []int) []floatgeom.Point4 {
+	if len(data) != len(remap) {
+		return data
+	}
+	out := make([]floatgeom.Point4, len(data))
+	for i, j := range remap {
+		out[i] = data[j]
+	}
+	return out
+}
+
+// polygonOfVertex returns the polygon each entry of a PolygonVertexIndex array belongs to
+func polygonOfVertex(origIndices []int) []int {
+	out := make([]int, len(origIndices))
+	poly := 0
+	for i, idx := range origIndices {
+		out[i] = poly
+		if idx < 0 {
+			poly++
+		}
+	}
+	return out
+}
+
+// directIndex resolves the data index for element i of a layer, honoring IndexToDirect indices
+func directIndex(i int, indices []int) int {
+	if len(indices) == 0 {
+		return i
+	}
+	if i < len(indices) {
+		return indices[i]
+	}
+	return -1
+}
+
+// expandVec2 spreads layer data to one value per polygon vertex, whatever its mapping
+func expandVec2(mapping VertexDataMapping, data []floatgeom.Point2, indices []int, origIndices []int) []floatgeom.Point2 {
+	if mapping != ByPolygon && mapping != AllSame {
+		return splatVec2(mapping, data, indices, origIndices)
+	}
+	out := make([]floatgeom.Point2, len(origIndices))
+	for i, poly := range polygonOfVertex(origIndices) {
+		if mapping == AllSame {
+			poly = 0
+		}
+		if j := directIndex(poly, indices); j >= 0 && j < len(data) {
+			out[i] = data[j]
+		}
+	}
+	return out
+}
+
+func expandVec3(mapping VertexDataMapping, data []floatgeom.Point3, indices []int, origIndices []int) []floatgeom.Point3 {
+	if mapping != ByPolygon && mapping != AllSame {
+		return splatVec3(mapping, data, indices, origIndices)
+	}
+	out := make([]floatgeom.Point3, len(origIndices))
+	for i, poly := range polygonOfVertex(origIndices) {
+		if mapping == AllSame {
+			poly = 0
+		}
+		if j := directIndex(poly, indices); j >= 0 && j < len(data) {
+			out[i] = data[j]
+		}
+	}
+	return out
+}
+
+func expandVec4(mapping VertexDataMapping, data []floatgeom.Point4, indices []int, origIndices []int) []floatgeom.Point4 {
+	if mapping != ByPolygon && mapping != AllSame {
+		return splatVec4(mapping, data, indices, origIndices)
+	}
+	out := make([]floatgeom.Point4, len(origIndices))
+	for i, poly := range polygonOfVertex(origIndices) {
+		if mapping == AllSame {
+			poly = 0
+		}
+		if j := directIndex(poly, indices); j >= 0 && j < len(data) {
+			out[i] = data[j]
+		}
+	}
+	return out
+}
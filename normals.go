@@ -0,0 +1,171 @@
+package ofbx
+
+import (
+	"math"
+
+	"github.com/oakmound/oak/v2/alg"
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/pkg/errors"
+)
+
+// cornerAngle returns the angle at p between the edges toward prev and next,
+// measured in the plane perpendicular to n when n is not zero
+func cornerAngle(prev, p, next, n floatgeom.Point3) float64 {
+	a, b := prev.Sub(p), next.Sub(p)
+	a = a.Sub(n.MulConst(n.Dot(a))).Normalize()
+	b = b.Sub(n.MulConst(n.Dot(b))).Normalize()
+	if a.Magnitude() == 0 || b.Magnitude() == 0 {
+		return 0
+	}
+	return math.Acos(math.Max(-1, math.Min(1, a.Dot(b))))
+}
+
+// perpendicular returns any unit vector perpendicular to n
+func perpendicular(n floatgeom.Point3) floatgeom.Point3 {
+	u, _ := planeBasis(n)
+	return u
+}
+
+// GenerateNormals replaces Normals with smooth normals computed from the polygons. The normal of a
+// polygon corner averages the polygons meeting at its control point, weighted by their corner angle,
+// but only those whose polygon normal is within smoothingAngle degrees of its own. A smoothingAngle of
+// zero gives flat shading and 180 smooths everything. When the geometry has smoothing groups or hard
+// edges, they decide which polygons are smoothed together and smoothingAngle is ignored.
+func (g *Geometry) GenerateNormals(smoothingAngle float64) {
+	corners := g.cornerControlPoints()
+	polygons := make([]int, len(corners))
+	weights := make([]float64, len(corners))
+	faceNormals := make([]floatgeom.Point3, len(g.Faces))
+	shared := make(map[int][]int)
+
+	pv := 0
+	for poly, face := range g.Faces {
+		valid := len(face) >= 3
+		for _, cp := range face {
+			valid = valid && cp >= 0 && cp < len(g.Vertices)
+		}
+		if valid {
+			points := make([]floatgeom.Point3, len(face))
+			for i, cp := range face {
+				points[i] = g.Vertices[cp]
+			}
+			n := polygonNormal(points, sequence(len(points))).Normalize()
+			faceNormals[poly] = n
+			for i, cp := range face {
+				weights[pv+i] = cornerAngle(points[(i+len(face)-1)%len(face)], points[i], points[(i+1)%len(face)], n)
+				shared[cp] = append(shared[cp], pv+i)
+			}
+		}
+		for i := range face {
+			polygons[pv+i] = poly
+		}
+		pv += len(face)
+	}
+
+	// Allow for rounding so that coplanar polygons are always smoothed together
+	minCos := math.Cos(smoothingAngle*alg.DegToRad) - 1e-9
+	neighbours := g.smoothingNeighbours()
+	normals := make([]floatgeom.Point3, len(corners))
+	for pv, cp := range corners {
+		n := faceNormals[polygons[pv]]
+		others := shared[cp]
+		if neighbours != nil {
+			others = neighbours[pv]
+		}
+		var sum floatgeom.Point3
+		for _, other := range others {
+			m := faceNormals[polygons[other]]
+			if neighbours != nil || n.Dot(m) >= minCos {
+				sum = sum.Add(m.MulConst(weights[other]))
+			}
+		}
+		if sum.Magnitude() == 0 {
+			sum = n
+		}
+		normals[pv] = sum.Normalize()
+	}
+	g.Normals = normals
+}
+
+type tangentSpaceKey struct {
+	position, normal floatgeom.Point3
+	uv               floatgeom.Point2
+	flipped          bool
+}
+
+// GenerateTangents replaces Tangents and BitangentSigns with a tangent basis computed from the
+// given UV set: per triangle tangents are projected onto the normal plane of each corner, weighted by
+// corner angle and shared between corners with the same position, normal, UV and UV orientation.
+// The bitangent of a corner is BitangentSigns[i] * cross(Normals[i], Tangents[i]), with the sign taken
+// from the UV winding of the whole polygon. This is close to MikkTSpace but not bit for bit the same,
+// so normal maps baked against MikkTSpace may show small seams. Normals must be present, see GenerateNormals.
+func (g *Geometry) GenerateTangents(uvSet int) error {
+	if uvSet < 0 || uvSet >= MaxUvs {
+		return errors.New("Invalid UV set")
+	}
+	corners := g.cornerControlPoints()
+	uvs := g.UVs[uvSet]
+	if len(uvs) < len(corners) {
+		return errors.New("Missing UVs")
+	}
+	if len(g.Normals) < len(corners) {
+		return errors.New("Missing normals")
+	}
+
+	tris := g.triangulateFaces(TriangulateEarClipping)
+	// A polygon is mirrored in UV space when its UV area is negative
+	uvArea := make([]float64, len(g.Faces))
+	for _, tri := range tris {
+		a, b, c := uvs[tri.corners[0]], uvs[tri.corners[1]], uvs[tri.corners[2]]
+		uvArea[tri.polygon] += cross2(a, b, c)
+	}
+
+	keyOf := func(pv, poly int) tangentSpaceKey {
+		return tangentSpaceKey{g.Vertices[corners[pv]], g.Normals[pv], uvs[pv], uvArea[poly] < 0}
+	}
+	sums := make(map[tangentSpaceKey]floatgeom.Point3)
+	for _, tri := range tris {
+		var p [3]floatgeom.Point3
+		var t [3]floatgeom.Point2
+		for i, pv := range tri.corners {
+			p[i], t[i] = g.Vertices[corners[pv]], uvs[pv]
+		}
+		d1, d2 := p[1].Sub(p[0]), p[2].Sub(p[0])
+		t1, t2 := t[1].Sub(t[0]), t[2].Sub(t[0])
+		os := d1.MulConst(t2[1]).Sub(d2.MulConst(t1[1])).Normalize()
+		if uvArea[tri.polygon] < 0 {
+			os = os.MulConst(-1)
+		}
+		for i, pv := range tri.corners {
+			n := g.Normals[pv]
+			projected := os.Sub(n.MulConst(n.Dot(os))).Normalize()
+			angle := cornerAngle(p[(i+2)%3], p[i], p[(i+1)%3], n)
+			key := keyOf(pv, tri.polygon)
+			sums[key] = sums[key].Add(projected.MulConst(angle))
+		}
+	}
+
+	tangents := make([]floatgeom.Point3, len(corners))
+	signs := make([]float64, len(corners))
+	pv := 0
+	for poly, face := range g.Faces {
+		for range face {
+			n := g.Normals[pv]
+			key := keyOf(pv, poly)
+			t := sums[key]
+			t = t.Sub(n.MulConst(n.Dot(t))).Normalize()
+			if t.Magnitude() == 0 {
+				t = perpendicular(n)
+			}
+			tangents[pv] = t
+			signs[pv] = 1
+			if key.flipped {
+				signs[pv] = -1
+			}
+			pv++
+		}
+	}
+	g.Tangents = tangents
+	g.BitangentSigns = signs
+	return nil
+}
@@ -0,0 +1,86 @@
+package ofbx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateNormalsCube(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	geom := scene.Meshes[0].Geometry
+	exported := append([]floatgeom.Point3{}, geom.Normals...)
+
+	// The cube is exported flat shaded, which any angle below 90 degrees reproduces
+	geom.GenerateNormals(30)
+	require.Len(t, geom.Normals, len(exported))
+	for i := range exported {
+		assertPointsInDelta(t, exported[i], geom.Normals[i])
+	}
+
+	// Fully smoothed, every corner points away from the center along the diagonal
+	geom.GenerateNormals(180)
+	for i, cp := range geom.cornerControlPoints() {
+		assertPointsInDelta(t, geom.Vertices[cp].Normalize(), geom.Normals[i])
+	}
+}
+
+func TestGenerateNormalsAngle(t *testing.T) {
+	// Two quads folded by 30 degrees along their shared edge
+	geom := foldedQuads()
+	geom.Edges = nil
+	s, c := math.Sin(math.Pi/6), math.Cos(math.Pi/6)
+
+	geom.GenerateNormals(45)
+	assertPointsInDelta(t, floatgeom.Point3{-math.Sin(math.Pi / 12), 0, math.Cos(math.Pi / 12)}, geom.Normals[0])
+	assertPointsInDelta(t, floatgeom.Point3{0, 0, 1}, geom.Normals[2])
+
+	geom.GenerateNormals(20)
+	assertPointsInDelta(t, floatgeom.Point3{0, 0, 1}, geom.Normals[0])
+	assertPointsInDelta(t, floatgeom.Point3{-s, 0, c}, geom.Normals[4])
+}
+
+func TestGenerateTangents(t *testing.T) {
+	geom := NewGeometry(&Scene{}, &Element{ID: NewDataView("Geometry")})
+	geom.Vertices = []floatgeom.Point3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {2, 0, 0}, {2, 1, 0}}
+	// The second quad is mirrored in U, as is common for symmetric models
+	geom.Faces = [][]int{{0, 1, 2, 3}, {1, 4, 5, 2}}
+	geom.UVs[0] = []floatgeom.Point2{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {1, 0}, {0, 0}, {0, 1}, {1, 1}}
+
+	assert.Error(t, geom.GenerateTangents(0))
+	geom.GenerateNormals(0)
+	assert.Error(t, geom.GenerateTangents(1))
+	assert.Error(t, geom.GenerateTangents(MaxUvs))
+	require.NoError(t, geom.GenerateTangents(0))
+
+	for i := 0; i < 4; i++ {
+		assertPointsInDelta(t, floatgeom.Point3{1, 0, 0}, geom.Tangents[i])
+		assert.Equal(t, 1.0, geom.BitangentSigns[i])
+	}
+	for i := 4; i < 8; i++ {
+		assertPointsInDelta(t, floatgeom.Point3{-1, 0, 0}, geom.Tangents[i])
+		assert.Equal(t, -1.0, geom.BitangentSigns[i])
+		// The bitangent still follows V
+		bitangent := geom.Normals[i].Cross(geom.Tangents[i]).MulConst(geom.BitangentSigns[i])
+		assertPointsInDelta(t, floatgeom.Point3{0, 1, 0}, bitangent)
+	}
+
+	tm := geom.BuildTriangleMesh(TriangleMeshOptions{})
+	assert.Equal(t, floatgeom.Point4{-1, 0, 0, -1}, tm.Tangents[len(tm.Tangents)-1])
+}
+
+func TestGenerateTangentsScene(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	for _, mesh := range scene.Meshes {
+		geom := mesh.Geometry
+		require.NoError(t, geom.GenerateTangents(0))
+		require.Len(t, geom.Tangents, len(geom.Normals))
+		for i, tangent := range geom.Tangents {
+			assert.InDelta(t, 1, tangent.Magnitude(), 1e-6)
+			assert.InDelta(t, 0, tangent.Dot(geom.Normals[i].Normalize()), 1e-6)
+		}
+	}
+}
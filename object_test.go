@@ -38,6 +38,10 @@ func TestObjectType(t *testing.T) {
 	if got := obj.Type(); got != NOTYPE {
 		t.Errorf("Object.Type() = %v, want %v", got, NOTYPE)
 	}
+	// New types are added after NOTYPE so existing values don't change
+	if NOTYPE != 14 {
+		t.Errorf("NOTYPE = %d, want 14", NOTYPE)
+	}
 }
 
 func TestObjectElement(t *testing.T) {
@@ -2,46 +2,48 @@ package ofbx
 
 import (
 	"compress/zlib"
+	"context"
 	"encoding/binary"
-	"fmt"
 	"io"
+	"io/ioutil"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/oakmound/oak/v2/alg/floatgeom"
 	"github.com/pkg/errors"
 )
 
-func ParseTemplates(root *Element) {
+// ParseTemplates returns the property templates of the file's definitions, keyed by
+// object type and template class, such as "MaterialFbxSurfacePhong"
+func ParseTemplates(root *Element) map[string]*Element {
+	templates := make(map[string]*Element)
 	defs := findChildren(root, "Definitions")
 	if len(defs) == 0 {
-		return
+		return templates
 	}
 
-	templates := make(map[string]*Element)
 	defs = defs[0].Children
 	for _, def := range defs {
 		if def.ID.String() == "ObjectType" {
-			prop1 := def.getProperty(0).value
-			prop1Data, err := io.ReadAll(prop1)
-			if err != nil && err != io.EOF {
-				//fmt.Println(err)
+			prop1 := def.getProperty(0)
+			if prop1 == nil {
 				continue
 			}
 			subdefs := def.Children
 			for _, subdef := range subdefs {
 				if subdef.ID.String() == "PropertyTemplate" {
-					prop2 := subdef.getProperty(0).value
-					prop2Data, err := io.ReadAll(prop2)
-					if err != nil && err != io.EOF {
-						//fmt.Println(err)
+					prop2 := subdef.getProperty(0)
+					if prop2 == nil {
 						continue
 					}
-					templates[string(prop1Data)+string(prop2Data)] = subdef
+					templates[prop1.value.String()+prop2.value.String()] = subdef
 				}
 			}
 
 		}
 	}
+	return templates
 }
 
 func parseBinaryArrayInt(property *Property) ([]int, error) {
@@ -55,6 +57,52 @@ func parseBinaryArrayInt(property *Property) ([]int, error) {
 	return parseArrayRawInt(property)
 }
 
+func parseBinaryArrayBool(property *Property) ([]bool, error) {
+	count := property.Count
+	if count == 0 {
+		return []bool{}, nil
+	}
+	if !property.Type.IsArray() {
+		return nil, errors.New("Invalid type")
+	}
+	if property.Type != ArrayBOOL && property.Type != ArrayBYTE {
+		ints, err := parseArrayRawInt(property)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]bool, len(ints))
+		for i, v := range ints {
+			out[i] = v != 0
+		}
+		return out, nil
+	}
+	byts, err := parseBinaryArrayByte(property)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]bool, count)
+	for i, b := range byts {
+		out[i] = b != 0
+	}
+	return out, nil
+}
+
+// parseBinaryArrayByte reads the raw bytes of a bool or byte array
+func parseBinaryArrayByte(property *Property) ([]byte, error) {
+	if property.Type != ArrayBOOL && property.Type != ArrayBYTE {
+		return nil, errors.New("Invalid type")
+	}
+	r, err := arrayReader(property)
+	if err != nil {
+		return nil, err
+	}
+	byts, err := readArrayData(r, property.Count, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read bool array")
+	}
+	return byts, nil
+}
+
 func parseBinaryArrayFloat64(property *Property) ([]float64, error) {
 	count := property.Count
 	if count == 0 {
@@ -84,7 +132,7 @@ func parseBinaryArrayVec2(property *Property) ([]floatgeom.Point2, error) {
 		return nil, err
 	}
 	vs := make([]floatgeom.Point2, len(f64s)/2)
-	for i := 0; i < len(f64s); i += 2 {
+	for i := 0; (i + 1) < len(f64s); i += 2 {
 		vs[i/2][0] = f64s[i]
 		vs[i/2][1] = f64s[i+1]
 	}
@@ -114,7 +162,7 @@ func parseBinaryArrayVec4(property *Property) ([]floatgeom.Point4, error) {
 		return nil, err
 	}
 	vs := make([]floatgeom.Point4, len(f64s)/4)
-	for i := 0; i < len(f64s); i += 4 {
+	for i := 0; (i + 3) < len(f64s); i += 4 {
 		vs[i/4][0] = f64s[i]
 		vs[i/4][1] = f64s[i+1]
 		vs[i/4][2] = f64s[i+2]
@@ -123,140 +171,157 @@ func parseBinaryArrayVec4(property *Property) ([]floatgeom.Point4, error) {
 	return vs, nil
 }
 
-func parseArrayRawInt(property *Property) ([]int, error) {
-	if property.Type == 'd' || property.Type == 'f' {
-		return nil, errors.New("Invalid type, expected i or l")
-	}
+// arrayReader returns a reader over the decoded contents of an array property
+func arrayReader(property *Property) (io.Reader, error) {
+	property.value.Seek(0, io.SeekStart)
 	switch property.Encoding {
 	case 0:
-		return parseArrayRawIntEnd(property.value, property.Count, property.Type.Size()), nil
+		return property.value, nil
 	case 1:
 		zr, err := zlib.NewReader(&property.value.Reader)
 		if err != nil {
 			return nil, errors.Wrap(err, "New Reader failed")
 		}
-		defer zr.Close()
-		return parseArrayRawIntEnd(zr, property.Count, property.Type.Size()), nil
+		// The tokenizer only budgets for what the count says the array inflates to
+		return io.LimitReader(zr, int64(property.Count*property.Type.Size())), nil
 	}
 	return nil, errors.New("Invalid encoding")
 }
 
-func parseArrayRawIntEnd(r io.Reader, ln int, elemSize int) []int {
-	if elemSize == 4 {
-		i32s := make([]int32, int(ln))
-		binary.Read(r, binary.LittleEndian, i32s)
-		out := make([]int, len(i32s))
-		for i, f := range i32s {
-			out[i] = int(f)
+func parseArrayRawInt(property *Property) ([]int, error) {
+	if property.Type == 'd' || property.Type == 'f' {
+		return nil, errors.New("Invalid type, expected i or l")
+	}
+	r, err := arrayReader(property)
+	if err != nil {
+		return nil, err
+	}
+	return parseArrayRawIntEnd(r, property.Count, property.Type.Size())
+}
+
+func parseArrayRawIntEnd(r io.Reader, ln int, elemSize int) ([]int, error) {
+	data, err := readArrayData(r, ln, elemSize)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]int, ln)
+	for i := range out {
+		if elemSize == 4 {
+			out[i] = int(int32(binary.LittleEndian.Uint32(data[i*4:])))
+		} else {
+			out[i] = int(int64(binary.LittleEndian.Uint64(data[i*8:])))
+		}
+	}
+	return out, nil
+}
+
+// readArrayData reads the ln elements of elemSize bytes of an array. The count comes from the file,
+// so the data is read before anything is allocated for it.
+func readArrayData(r io.Reader, ln int, elemSize int) ([]byte, error) {
+	if ln < 0 {
+		return nil, errors.Errorf("Invalid array length %d", ln)
+	}
+	size := int64(ln) * int64(elemSize)
+	if lr, ok := r.(interface{ Len() int }); ok {
+		// Uncompressed data is all there already
+		if int64(lr.Len()) < size {
+			return nil, errors.Wrapf(io.ErrUnexpectedEOF, "Failed to read array of %d elements", ln)
 		}
-		return out
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, errors.Wrap(err, "Failed to read array")
+		}
+		return data, nil
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(r, size))
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to read array")
 	}
-	i64s := make([]int64, int(ln))
-	binary.Read(r, binary.LittleEndian, i64s)
-	out := make([]int, len(i64s))
-	for i, f := range i64s {
-		out[i] = int(f)
+	if int64(len(data)) < size {
+		return nil, errors.Wrapf(io.ErrUnexpectedEOF, "Failed to read array of %d elements", ln)
 	}
-	return out
+	return data, nil
 }
 
 func parseArrayRawInt64(property *Property) ([]int64, error) {
 	if property.Type == 'd' || property.Type == 'f' {
 		return nil, errors.New("Invalid type, expected i or l")
 	}
-	switch property.Encoding {
-	case 0:
-		return parseArrayRawInt64End(property.value, property.Count, property.Type.Size()), nil
-	case 1:
-		zr, err := zlib.NewReader(&property.value.Reader)
-		if err != nil {
-			return nil, errors.Wrap(err, "New Reader failed")
-		}
-		defer zr.Close()
-		return parseArrayRawInt64End(zr, property.Count, property.Type.Size()), nil
+	r, err := arrayReader(property)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("Invalid encoding")
+	return parseArrayRawInt64End(r, property.Count, property.Type.Size())
 }
 
-func parseArrayRawInt64End(r io.Reader, ln int, elemSize int) []int64 {
-	if elemSize == 4 {
-		i32s := make([]int32, int(ln))
-		binary.Read(r, binary.LittleEndian, i32s)
-		out := make([]int64, len(i32s))
-		for i, f := range i32s {
-			out[i] = int64(f)
+func parseArrayRawInt64End(r io.Reader, ln int, elemSize int) ([]int64, error) {
+	data, err := readArrayData(r, ln, elemSize)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]int64, ln)
+	for i := range out {
+		if elemSize == 4 {
+			out[i] = int64(int32(binary.LittleEndian.Uint32(data[i*4:])))
+		} else {
+			out[i] = int64(binary.LittleEndian.Uint64(data[i*8:]))
 		}
-		return out
 	}
-	out := make([]int64, int(ln))
-	binary.Read(r, binary.LittleEndian, out)
-	return out
+	return out, nil
 }
 
 func parseArrayRawFloat32(property *Property) ([]float32, error) {
 	if property.Type == 'i' || property.Type == 'l' {
 		return nil, errors.New("Invalid type, expected d or f")
 	}
-	switch property.Encoding {
-	case 0:
-		return parseArrayRawFloat32End(property.value, property.Count, property.Type.Size()), nil
-	case 1:
-		zr, err := zlib.NewReader(&property.value.Reader)
-		if err != nil {
-			return nil, errors.Wrap(err, "New Reader failed")
-		}
-		defer zr.Close()
-		return parseArrayRawFloat32End(zr, property.Count, property.Type.Size()), nil
+	r, err := arrayReader(property)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("Invalid encoding")
+	return parseArrayRawFloat32End(r, property.Count, property.Type.Size())
 }
 
-func parseArrayRawFloat32End(r io.Reader, ln int, elemSize int) []float32 {
-	if elemSize == 4 {
-		out := make([]float32, int(ln))
-		binary.Read(r, binary.LittleEndian, out)
-		return out
+func parseArrayRawFloat32End(r io.Reader, ln int, elemSize int) ([]float32, error) {
+	data, err := readArrayData(r, ln, elemSize)
+	if err != nil {
+		return nil, err
 	}
-	f64s := make([]float64, int(ln))
-	binary.Read(r, binary.LittleEndian, f64s)
-	out := make([]float32, len(f64s))
-	for i, f := range f64s {
-		out[i] = float32(f)
+	out := make([]float32, ln)
+	for i := range out {
+		if elemSize == 4 {
+			out[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+		} else {
+			out[i] = float32(math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:])))
+		}
 	}
-	return out
+	return out, nil
 }
 
 func parseArrayRawFloat64(property *Property) ([]float64, error) {
 	if property.Type == 'i' || property.Type == 'l' {
 		return nil, errors.New("Invalid type, expected d or f")
 	}
-	switch property.Encoding {
-	case 0:
-		return parseArrayRawFloat64End(property.value, property.Count, property.Type.Size()), nil
-	case 1:
-		zr, err := zlib.NewReader(&property.value.Reader)
-		if err != nil {
-			return nil, errors.Wrap(err, "New Reader failed")
-		}
-		defer zr.Close()
-		return parseArrayRawFloat64End(zr, property.Count, property.Type.Size()), nil
+	r, err := arrayReader(property)
+	if err != nil {
+		return nil, err
 	}
-	return nil, errors.New("Invalid encoding")
+	return parseArrayRawFloat64End(r, property.Count, property.Type.Size())
 }
 
-func parseArrayRawFloat64End(r io.Reader, ln int, elemSize int) []float64 {
-	if elemSize == 4 {
-		f32s := make([]float32, int(ln))
-		binary.Read(r, binary.LittleEndian, f32s)
-		out := make([]float64, len(f32s))
-		for i, f := range f32s {
-			out[i] = float64(f)
+func parseArrayRawFloat64End(r io.Reader, ln int, elemSize int) ([]float64, error) {
+	data, err := readArrayData(r, ln, elemSize)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]float64, ln)
+	for i := range out {
+		if elemSize == 4 {
+			out[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:])))
+		} else {
+			out[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[i*8:]))
 		}
-		return out
 	}
-	out := make([]float64, int(ln))
-	binary.Read(r, binary.LittleEndian, out)
-	return out
+	return out, nil
 }
 
 func parseDoubleVecDataVec2(property *Property) ([]floatgeom.Point2, error) {
@@ -367,7 +432,7 @@ func parseVertexDataInner(element *Element, name, idxName string) ([]int, Vertex
 			indicesProp := findChildProperty(element, idxName)
 			if len(indicesProp) != 0 {
 				if idxs, err = parseBinaryArrayInt(indicesProp[0]); err != nil {
-					return nil, 0, nil, errors.New("Unable to parse indices")
+					return nil, 0, nil, errors.Wrap(err, "Unable to parse indices")
 				}
 			} else {
 				// just use indicies in order.
@@ -384,9 +449,110 @@ func parseTexture(scene *Scene, element *Element) *Texture {
 	texture := NewTexture(scene, element)
 	assignSingleChildProperty(element, "FileName", &texture.filename)
 	assignSingleChildProperty(element, "RelativeFilename", &texture.relativeFilename)
+	if alphaSource := findSingleChildProperty(element, "Texture_Alpha_Source"); alphaSource != nil {
+		texture.TextureAlphaSource = alphaSource.value.String()
+	}
+	if cropping := findChildProperty(element, "Cropping"); len(cropping) == 4 {
+		for i, p := range cropping {
+			texture.Cropping[i] = int(p.toInt64())
+		}
+	}
+	// Older files only carry the UV transform as ModelUVTranslation and ModelUVScaling
+	if translation := findChildProperty(element, "ModelUVTranslation"); len(translation) == 2 {
+		texture.Translation[0] = translation[0].toFloat64()
+		texture.Translation[1] = translation[1].toFloat64()
+	}
+	if scaling := findChildProperty(element, "ModelUVScaling"); len(scaling) == 2 {
+		texture.Scaling[0] = scaling[0].toFloat64()
+		texture.Scaling[1] = scaling[1].toFloat64()
+	}
+
+	elems := findChildren(element, "Properties70")
+	if len(elems) == 0 {
+		return texture
+	}
+	for _, elem := range elems[0].Children {
+		if elem.getProperty(0) == nil || elem.getProperty(4) == nil {
+			continue
+		}
+		vec3 := func() floatgeom.Point3 {
+			if len(elem.Properties) < 7 {
+				return floatgeom.Point3{}
+			}
+			return floatgeom.Point3{
+				elem.getProperty(4).toFloat64(),
+				elem.getProperty(5).toFloat64(),
+				elem.getProperty(6).toFloat64(),
+			}
+		}
+		switch elem.getProperty(0).value.String() {
+		case "UVSet":
+			texture.UVSet = elem.getProperty(4).value.String()
+		case "WrapModeU":
+			texture.WrapModeU = WrapMode(elem.getProperty(4).toInt64())
+		case "WrapModeV":
+			texture.WrapModeV = WrapMode(elem.getProperty(4).toInt64())
+		case "Translation":
+			texture.Translation = vec3()
+		case "Rotation":
+			texture.Rotation = vec3()
+		case "Scaling":
+			texture.Scaling = vec3()
+		case "TextureRotationPivot":
+			texture.RotationPivot = vec3()
+		case "TextureScalingPivot":
+			texture.ScalingPivot = vec3()
+		case "UVSwap":
+			texture.UVSwap = elem.getProperty(4).toInt64() != 0
+		case "UseMipMap":
+			texture.UseMipMap = elem.getProperty(4).toInt64() != 0
+		case "AlphaSource":
+			texture.AlphaSource = AlphaSource(elem.getProperty(4).toInt64())
+		case "PremultiplyAlpha":
+			texture.PremultiplyAlpha = elem.getProperty(4).toInt64() != 0
+		}
+	}
 	return texture
 }
 
+func parseVideo(scene *Scene, element *Element) *Video {
+	video := NewVideo(scene, element)
+	if filename := findSingleChildProperty(element, "Filename"); filename != nil {
+		video.Filename = filename.value.String()
+	} else {
+		video.Filename = resolveStringProperty(video, "Path", "")
+	}
+	if relative := findSingleChildProperty(element, "RelativeFilename"); relative != nil {
+		video.RelativeFilename = relative.value.String()
+	} else {
+		video.RelativeFilename = resolveStringProperty(video, "RelPath", "")
+	}
+	if content := findSingleChildProperty(element, "Content"); content != nil && content.Type == RAWSTRING {
+		video.Content = []byte(content.value.String())
+	}
+	return video
+}
+
+func parseLayeredTexture(scene *Scene, element *Element) (*LayeredTexture, error) {
+	lt := NewLayeredTexture(scene, element)
+	if blendModes := findSingleChildProperty(element, "BlendModes"); blendModes != nil {
+		modes, err := parseBinaryArrayInt(blendModes)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid layered texture: blend modes error")
+		}
+		for _, mode := range modes {
+			lt.BlendModes = append(lt.BlendModes, BlendMode(mode))
+		}
+	}
+	if alphas := findSingleChildProperty(element, "Alphas"); alphas != nil {
+		var err error
+		if lt.Alphas, err = parseBinaryArrayFloat64(alphas); err != nil {
+			return nil, errors.Wrap(err, "Invalid layered texture: alphas error")
+		}
+	}
+	return lt, nil
+}
+
 func parseLimbNode(scene *Scene, element *Element) (*Node, error) {
 	if prop := element.getProperty(2); prop == nil || prop.value.String() != "LimbNode" {
 		return nil, errors.New("Invalid limb node")
@@ -403,62 +569,132 @@ func parseMesh(scene *Scene, element *Element) (*Mesh, error) {
 
 func parseMaterial(scene *Scene, element *Element) *Material {
 	material := NewMaterial(scene, element)
-	elems := findChildren(element, "Properties70")
-	material.DiffuseColor = Color{1, 1, 1}
-	if len(elems) == 0 {
-		return material
+	// The FBX SDK's FbxSurfacePhong defaults, for files without a template
+	material.AmbientColor = Color{0.2, 0.2, 0.2}
+	material.AmbientFactor = 1
+	material.DiffuseColor = Color{0.8, 0.8, 0.8}
+	material.DiffuseFactor = 1
+	material.EmissiveFactor = 1
+	material.Opacity = 1
+	material.BumpFactor = 1
+	material.DisplacementFactor = 1
+	material.VectorDisplacementFactor = 1
+	material.SpecularColor = Color{0.2, 0.2, 0.2}
+	material.SpecularFactor = 1
+	material.Shininess = 20
+	material.ShininessExponent = 20
+	material.ReflectionFactor = 1
+
+	shadingModel := ""
+	if prop := findSingleChildProperty(element, "ShadingModel"); prop != nil {
+		shadingModel = prop.value.String()
+	} else if prop := resolveProperty(material, "ShadingModel"); prop != nil && prop.getProperty(4) != nil {
+		shadingModel = prop.getProperty(4).value.String()
+	}
+	switch strings.ToLower(shadingModel) {
+	case "lambert":
+		material.ShadingModel = ShadingLambert
+	case "phong", "":
+		material.ShadingModel = ShadingPhong
+	}
+	if prop := findSingleChildProperty(element, "MultiLayer"); prop != nil {
+		material.MultiLayer = prop.toInt64() != 0
+	}
+
+	if template := materialTemplate(scene, material.ShadingModel); template != nil {
+		if elems := findChildren(template, "Properties70"); len(elems) != 0 {
+			setMaterialProperties(material, elems[0].Children)
+		}
+	}
+	if elems := findChildren(element, "Properties70"); len(elems) != 0 {
+		setMaterialProperties(material, elems[0].Children)
+	}
+	return material
+}
+
+// materialTemplate returns the file's property template for the shading model, or nil
+func materialTemplate(scene *Scene, model ShadingModel) *Element {
+	if scene == nil {
+		return nil
+	}
+	classes := []string{"FbxSurfacePhong", "FbxSurfaceLambert"}
+	if model == ShadingLambert {
+		classes = []string{"FbxSurfaceLambert", "FbxSurfacePhong"}
+	}
+	for _, class := range classes {
+		if template := scene.templates["Material"+class]; template != nil {
+			return template
+		}
+	}
+	return nil
+}
+
+func setMaterialProperties(material *Material, elems []*Element) {
+	color := func(elem *Element) Color {
+		if len(elem.Properties) < 7 {
+			return Color{}
+		}
+		return Color{
+			R: float32(elem.getProperty(4).toFloat64()),
+			G: float32(elem.getProperty(5).toFloat64()),
+			B: float32(elem.getProperty(6).toFloat64()),
+		}
 	}
-	elems = elems[0].Children
-	// Todo: reflection / struct tags for these types of values
 	for _, elem := range elems {
 		if elem.getProperty(0) == nil {
 			continue
 		}
-		v := elem.getProperty(0).value.String()
-		// Commented out cases are things I (200sc) think might exist
-		// but haven't seen
-		switch v {
+		number := 0.0
+		if x := elem.getProperty(4); x != nil {
+			number = x.toFloat64()
+		}
+		switch elem.getProperty(0).value.String() {
 		case "EmissiveColor":
-			material.EmissiveColor.R = float32(elem.getProperty(4).value.toDouble())
-			material.EmissiveColor.G = float32(elem.getProperty(5).value.toDouble())
-			material.EmissiveColor.B = float32(elem.getProperty(6).value.toDouble())
+			material.EmissiveColor = color(elem)
+		case "EmissiveFactor":
+			material.EmissiveFactor = number
 		case "AmbientColor":
-			material.AmbientColor.R = float32(elem.getProperty(4).value.toDouble())
-			material.AmbientColor.G = float32(elem.getProperty(5).value.toDouble())
-			material.AmbientColor.B = float32(elem.getProperty(6).value.toDouble())
+			material.AmbientColor = color(elem)
+		case "AmbientFactor":
+			material.AmbientFactor = number
 		case "DiffuseColor":
-			material.DiffuseColor.R = float32(elem.getProperty(4).value.toDouble())
-			material.DiffuseColor.G = float32(elem.getProperty(5).value.toDouble())
-			material.DiffuseColor.B = float32(elem.getProperty(6).value.toDouble())
+			material.DiffuseColor = color(elem)
+		case "DiffuseFactor":
+			material.DiffuseFactor = number
 		case "TransparentColor":
-			material.TransparentColor.R = float32(elem.getProperty(4).value.toDouble())
-			material.TransparentColor.G = float32(elem.getProperty(5).value.toDouble())
-			material.TransparentColor.B = float32(elem.getProperty(6).value.toDouble())
+			material.TransparentColor = color(elem)
+		case "TransparencyFactor":
+			material.TransparencyFactor = number
+		case "Opacity":
+			material.Opacity = number
+		case "Bump":
+			material.Bump = color(elem)
+		case "BumpFactor":
+			material.BumpFactor = number
+		case "NormalMap":
+			material.NormalMap = color(elem)
+		case "DisplacementColor":
+			material.DisplacementColor = color(elem)
+		case "DisplacementFactor":
+			material.DisplacementFactor = number
+		case "VectorDisplacementColor":
+			material.VectorDisplacementColor = color(elem)
+		case "VectorDisplacementFactor":
+			material.VectorDisplacementFactor = number
 		case "SpecularColor":
-			material.SpecularColor.R = float32(elem.getProperty(4).value.toDouble())
-			material.SpecularColor.G = float32(elem.getProperty(5).value.toDouble())
-			material.SpecularColor.B = float32(elem.getProperty(6).value.toDouble())
-		case "ReflectionColor":
-			material.ReflectionColor.R = float32(elem.getProperty(4).value.toDouble())
-			material.ReflectionColor.G = float32(elem.getProperty(5).value.toDouble())
-			material.ReflectionColor.B = float32(elem.getProperty(6).value.toDouble())
-		case "EmissiveFactor":
-			material.EmissiveFactor = elem.getProperty(4).value.toDouble()
-		// case "AmbientFactor":
-		case "DiffuseFactor":
-			material.DiffuseFactor = elem.getProperty(4).value.toDouble()
-		// case "TransparentFactor":
+			material.SpecularColor = color(elem)
 		case "SpecularFactor":
-			material.SpecularFactor = elem.getProperty(4).value.toDouble()
+			material.SpecularFactor = number
+		case "ReflectionColor":
+			material.ReflectionColor = color(elem)
 		case "ReflectionFactor":
-			material.ReflectionFactor = elem.getProperty(4).value.toDouble()
+			material.ReflectionFactor = number
 		case "Shininess":
-			material.Shininess = elem.getProperty(4).value.toDouble()
+			material.Shininess = number
 		case "ShininessExponent":
-			material.ShininessExponent = elem.getProperty(4).value.toDouble()
+			material.ShininessExponent = number
 		}
 	}
-	return material
 }
 
 func parseAnimationCurve(scene *Scene, element *Element) (*AnimationCurve, error) {
@@ -506,23 +742,37 @@ func parseAnimationCurve(scene *Scene, element *Element) (*AnimationCurve, error
 
 func parseConnection(root *Element, scene *Scene) (bool, error) {
 	connections := findChildren(root, "Connections")
-	if connections == nil {
+	if len(connections) == 0 {
 		return true, nil
 	}
 
-	connections = connections[0].Children
-	for _, connection := range connections {
+	parent := connections[0]
+	for _, connection := range parent.Children {
+		fail := func(err error) error {
+			return scene.tolerate(WarningInvalidConnection, 0, elementError(parent, elementError(connection, err)))
+		}
 		prop0 := connection.getProperty(0)
 		prop1 := connection.getProperty(1)
 		prop2 := connection.getProperty(2)
 		if !isString(prop0) ||
 			!isLong(prop1) ||
 			!isLong(prop2) {
-			return false, errors.New("Invalid connection")
+			if err := fail(errors.New("Invalid connection")); err != nil {
+				return false, err
+			}
+			continue
 		}
 		var c Connection
-		c.from = prop1.value.touint64()
-		c.to = prop2.value.touint64()
+		err := prop1.value.read(&c.from)
+		if err == nil {
+			err = prop2.value.read(&c.to)
+		}
+		if err != nil {
+			if err := fail(errors.Wrap(err, "Invalid connection")); err != nil {
+				return false, err
+			}
+			continue
+		}
 		if prop0.value.String() == "OO" {
 			c.typ = ObjectConn
 		} else if prop0.value.String() == "OP" {
@@ -530,10 +780,16 @@ func parseConnection(root *Element, scene *Scene) (bool, error) {
 			if prop3 := connection.getProperty(3); prop3 != nil {
 				c.property = prop3.value.String()
 			} else {
-				return false, errors.New("Invalid connection")
+				if err := fail(errors.New("Invalid connection: missing property")); err != nil {
+					return false, err
+				}
+				continue
 			}
 		} else {
-			return false, errors.New("Not supported")
+			if err := fail(errors.Errorf("Not supported connection type %q", prop0.value.String())); err != nil {
+				return false, err
+			}
+			continue
 		}
 		scene.Connections = append(scene.Connections, c)
 	}
@@ -542,32 +798,42 @@ func parseConnection(root *Element, scene *Scene) (bool, error) {
 
 func parseTakes(scene *Scene) (bool, error) {
 	takes := findChildren(scene.RootElement, "Takes")
-	if len(takes) == 0 {
+	if len(takes) == 0 || scene.options.NoAnimation {
 		return true, nil
 	}
 
-	objects := takes[0].Children
-
-	for _, object := range objects {
+	for _, object := range takes[0].Children {
 		if object.ID.String() != "Take" {
 			continue
 		}
+		fail := func(err error) error {
+			return scene.tolerate(WarningInvalidTake, 0, elementError(takes[0], elementError(object, err)))
+		}
 		if !isString(object.getProperty(0)) {
-			return false, errors.New("Invalid name in take")
+			if err := fail(errors.New("Invalid name in take")); err != nil {
+				return false, err
+			}
+			continue
 		}
 		var take TakeInfo
 		take.name = object.getProperty(0).value
 		filename := findSingleChildProperty(object, "FileName")
 		if filename != nil {
 			if !isString(filename) {
-				return false, errors.New("Invalid filename in take")
+				if err := fail(errors.New("Invalid filename in take")); err != nil {
+					return false, err
+				}
+				continue
 			}
 			take.filename = filename.value
 		}
 		localTime := findChildProperty(object, "LocalTime")
 		if len(localTime) != 0 {
 			if !isLong(localTime[0]) || len(localTime) < 2 || !isLong(localTime[1]) {
-				return false, errors.New("Invalid local time in take")
+				if err := fail(errors.New("Invalid local time in take")); err != nil {
+					return false, err
+				}
+				continue
 			}
 
 			take.localTimeFrom = fbxTimeToSeconds(localTime[0].value.toint64())
@@ -576,7 +842,10 @@ func parseTakes(scene *Scene) (bool, error) {
 		refTime := findChildProperty(object, "ReferenceTime")
 		if len(refTime) != 0 {
 			if !isLong(refTime[0]) || len(refTime) < 2 || !isLong(refTime[1]) {
-				return false, errors.New("Invalid reference time in take")
+				if err := fail(errors.New("Invalid reference time in take")); err != nil {
+					return false, err
+				}
+				continue
 			}
 			take.refTimeFrom = fbxTimeToSeconds(refTime[0].value.toint64())
 			take.refTimeTo = fbxTimeToSeconds(refTime[1].value.toint64())
@@ -645,7 +914,90 @@ func parseGlobalSettings(root *Element, scene *Scene) {
 	scene.FrameRate = GetFramerateFromTimeMode(scene.Settings.TimeMode, scene.Settings.CustomFrameRate)
 }
 
-func parseObjects(root *Element, scene *Scene) (bool, error) {
+// parseObject makes the object an element describes, or returns nil for elements that aren't supported
+func parseObject(scene *Scene, elem *Element) (Obj, error) {
+	var obj Obj
+	var err error
+	switch elem.ID.String() {
+	case "Geometry":
+		lastProp := elem.getProperty(len(elem.Properties) - 1)
+		if lastProp != nil && lastProp.value.String() == "Mesh" {
+			obj, err = parseGeometry(scene, elem)
+			if err != nil {
+				return nil, err
+			}
+		}
+	case "Material":
+		obj = parseMaterial(scene, elem)
+	case "AnimationStack":
+		obj = NewAnimationStack(scene, elem)
+		stack := obj.(*AnimationStack)
+		scene.AnimationStacks = append(scene.AnimationStacks, stack)
+	case "AnimationLayer":
+		obj = NewAnimationLayer(scene, elem)
+	case "AnimationCurve":
+		obj, err = parseAnimationCurve(scene, elem)
+		if err != nil {
+			return nil, err
+		}
+	case "AnimationCurveNode":
+		obj = NewAnimationCurveNode(scene, elem)
+	case "Deformer":
+		classProp := elem.getProperty(2)
+		if classProp != nil {
+			v := classProp.value.String()
+			switch v {
+			case "Cluster":
+				obj, err = parseCluster(scene, elem)
+				if err != nil {
+					return nil, err
+				}
+			case "Skin":
+				obj = NewSkin(scene, elem)
+			}
+		}
+	case "NodeAttribute":
+		obj, err = parseNodeAttribute(scene, elem)
+		if err != nil {
+			return nil, err
+		}
+	case "Model":
+		classProp := elem.getProperty(2)
+		if classProp != nil {
+			v := classProp.value.String()
+			switch v {
+			case "Mesh":
+				mesh, err := parseMesh(scene, elem)
+				if err != nil {
+					return nil, err
+				}
+				scene.Meshes = append(scene.Meshes, mesh)
+				obj = mesh
+			case "LimbNode":
+				obj, err = parseLimbNode(scene, elem)
+				if err != nil {
+					return nil, err
+				}
+			case "Null", "Root":
+				obj = NewNode(scene, elem, NULL_NODE)
+			}
+		}
+	case "Texture":
+		obj = parseTexture(scene, elem)
+	case "Video":
+		video := parseVideo(scene, elem)
+		scene.Videos = append(scene.Videos, video)
+		obj = video
+	case "LayeredTexture":
+		obj, err = parseLayeredTexture(scene, elem)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return obj, nil
+}
+
+func parseObjects(ctx context.Context, root *Element, scene *Scene) (bool, error) {
 	//fmt.Println("Starting object Parse")
 	objs := findChildren(root, "Objects")
 	if len(objs) == 0 {
@@ -654,86 +1006,36 @@ func parseObjects(root *Element, scene *Scene) (bool, error) {
 	scene.RootNode = NewNode(scene, root, ROOT)
 	scene.ObjectMap[0] = scene.RootNode
 
-	objs = objs[0].Children
-	for _, elem := range objs {
+	objects := objs[0]
+	for _, elem := range objects.Children {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
 		if !isLong(elem.getProperty(0)) {
-			return false, errors.New("Invalid")
+			if err := scene.tolerate(WarningInvalidObject, 0, objectError(objects, elem, 0, errors.New("Invalid object ID"))); err != nil {
+				return false, err
+			}
+			continue
+		}
+		var id uint64
+		if err := elem.getProperty(0).value.read(&id); err != nil {
+			if err := scene.tolerate(WarningInvalidObject, 0, objectError(objects, elem, 0, err)); err != nil {
+				return false, err
+			}
+			continue
 		}
-		id := elem.getProperty(0).value.touint64()
 
-		var obj Obj
-		var err error
 		// This shouldn't happen?
 		// Original library had a check like this but it seems nonsensical
-		if id == 0 {
+		if id == 0 || scene.options.skipsElement(elem.ID.String()) {
 			continue
 		}
-		switch elem.ID.String() {
-		case "Geometry":
-			lastProp := elem.getProperty(len(elem.Properties) - 1)
-			if lastProp != nil && lastProp.value.String() == "Mesh" {
-				obj, err = parseGeometry(scene, elem)
-				if err != nil {
-					return false, err
-				}
-			}
-		case "Material":
-			obj = parseMaterial(scene, elem)
-		case "AnimationStack":
-			obj = NewAnimationStack(scene, elem)
-			stack := obj.(*AnimationStack)
-			scene.AnimationStacks = append(scene.AnimationStacks, stack)
-		case "AnimationLayer":
-			obj = NewAnimationLayer(scene, elem)
-		case "AnimationCurve":
-			obj, err = parseAnimationCurve(scene, elem)
-			if err != nil {
-				return false, err
-			}
-		case "AnimationCurveNode":
-			obj = NewAnimationCurveNode(scene, elem)
-		case "Deformer":
-			classProp := elem.getProperty(2)
-			if classProp != nil {
-				v := classProp.value.String()
-				switch v {
-				case "Cluster":
-					obj, err = parseCluster(scene, elem)
-					if err != nil {
-						return false, err
-					}
-				case "Skin":
-					obj = NewSkin(scene, elem)
-				}
-			}
-		case "NodeAttribute":
-			obj, err = parseNodeAttribute(scene, elem)
-			if err != nil {
+		obj, err := parseObject(scene, elem)
+		if err != nil {
+			if err := scene.tolerate(WarningInvalidObject, id, objectError(objects, elem, id, err)); err != nil {
 				return false, err
 			}
-		case "Model":
-			classProp := elem.getProperty(2)
-			if classProp != nil {
-				v := classProp.value.String()
-				switch v {
-				case "Mesh":
-					obj, err = parseMesh(scene, elem)
-					if err == nil {
-						mesh := obj.(*Mesh)
-						scene.Meshes = append(scene.Meshes, mesh)
-						obj = mesh
-					}
-				case "LimbNode":
-					obj, err = parseLimbNode(scene, elem)
-					if err != nil {
-						return false, err
-					}
-				case "Null", "Root":
-					obj = NewNode(scene, elem, NULL_NODE)
-				}
-			}
-		case "Texture":
-			obj = parseTexture(scene, elem)
+			continue
 		}
 
 		scene.ObjectMap[id] = obj
@@ -756,7 +1058,10 @@ func parseObjects(root *Element, scene *Scene) (bool, error) {
 		switch ctyp {
 		case NODE_ATTRIBUTE:
 			if parent.NodeAttribute() != nil {
-				return false, errors.New("Invalid node attribute")
+				if err := scene.tolerate(WarningDuplicateNodeAttribute, con.to, objectError(objects, parent.Element(), con.to, errors.Errorf("Invalid node attribute: connection from %d", con.from))); err != nil {
+					return false, err
+				}
+				continue
 			}
 			parent.SetNodeAttribute(child) //previously asserted that the child was a nodeattribute
 		case ANIMATION_CURVE_NODE:
@@ -773,7 +1078,10 @@ func parseObjects(root *Element, scene *Scene) (bool, error) {
 			switch ctyp {
 			case GEOMETRY:
 				if mesh.Geometry != nil {
-					return false, errors.New("Invalid mesh")
+					if err := scene.tolerate(WarningDuplicateGeometry, con.to, objectError(objects, parent.Element(), con.to, errors.Errorf("Invalid mesh: connection from %d", con.from))); err != nil {
+						return false, err
+					}
+					continue
 				}
 				mesh.Geometry = child.(*Geometry)
 			case MATERIAL:
@@ -783,29 +1091,31 @@ func parseObjects(root *Element, scene *Scene) (bool, error) {
 			skin := parent.(*Skin)
 			if ctyp == CLUSTER {
 				cluster := child.(*Cluster)
-				skin.Clusters = append(skin.Clusters, cluster)
 				if cluster.Skin != nil {
-					return false, errors.New("Cluster assigned to multiple skins")
+					if err := scene.tolerate(WarningMultipleSkins, con.to, objectError(objects, parent.Element(), con.to, errors.Errorf("Cluster assigned to multiple skins: connection from %d", con.from))); err != nil {
+						return false, err
+					}
+					continue
 				}
+				skin.Clusters = append(skin.Clusters, cluster)
 				cluster.Skin = skin
 			}
 		case MATERIAL:
 			mat := parent.(*Material)
+			if ctyp == TEXTURE && con.property != "" {
+				mat.addTexture(con.property, child.(*Texture))
+			}
+			if ctyp == LAYERED_TEXTURE && con.property != "" {
+				mat.addLayeredTexture(con.property, child.(*LayeredTexture))
+			}
+		case TEXTURE:
+			if ctyp == VIDEO {
+				parent.(*Texture).video = child.(*Video)
+			}
+		case LAYERED_TEXTURE:
 			if ctyp == TEXTURE {
-				ttyp := TextureCOUNT
-				switch con.property {
-				case "NormalMap":
-					ttyp = NORMAL
-				case "DiffuseColor":
-					ttyp = DIFFUSE
-				}
-				if ttyp == TextureCOUNT {
-					break
-				}
-				if mat.Textures[ttyp] != nil {
-					break
-				}
-				mat.Textures[ttyp] = child.(*Texture)
+				lt := parent.(*LayeredTexture)
+				lt.Textures = append(lt.Textures, child.(*Texture))
 			}
 		case GEOMETRY:
 			geom := parent.(*Geometry)
@@ -816,7 +1126,10 @@ func parseObjects(root *Element, scene *Scene) (bool, error) {
 			cluster := parent.(*Cluster)
 			if ctyp == LIMB_NODE || ctyp == MESH || ctyp == NULL_NODE {
 				if cluster.Link != nil {
-					return false, errors.New("Invalid cluster")
+					if err := scene.tolerate(WarningDuplicateLink, con.to, objectError(objects, parent.Element(), con.to, errors.Errorf("Invalid cluster: connection from %d", con.from))); err != nil {
+						return false, err
+					}
+					continue
 				}
 				cluster.Link = child
 			}
@@ -840,7 +1153,10 @@ func parseObjects(root *Element, scene *Scene) (bool, error) {
 					node.Curves[2].connection = &con
 					node.Curves[2].Curve = child.(*AnimationCurve)
 				} else {
-					return false, errors.New("Invalid animation node")
+					if err := scene.tolerate(WarningTooManyCurves, con.to, objectError(objects, parent.Element(), con.to, errors.Errorf("Invalid animation node: connection from %d", con.from))); err != nil {
+						return false, err
+					}
+					continue
 				}
 			}
 		}
@@ -852,7 +1168,9 @@ func parseObjects(root *Element, scene *Scene) (bool, error) {
 		}
 		if ppr, ok := obj.(NeedsPostProcessing); ok {
 			if !ppr.postProcess() {
-				return false, errors.New("Failed to postprocess object" + fmt.Sprintf("%v", obj.ID()))
+				if err := scene.tolerate(WarningPostProcess, obj.ID(), objectError(objects, obj.Element(), obj.ID(), errors.New("Failed to postprocess object"))); err != nil {
+					return false, err
+				}
 			}
 		}
 	}
@@ -0,0 +1,80 @@
+package ofbx
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ParseError describes why and where a file failed to parse
+type ParseError struct {
+	// Offset is the position in the file of the failed read, or of the element that failed to
+	// parse. It is 0 when unknown, as no element starts at 0.
+	Offset int64
+	// Path names the element from the top of the file, such as "Objects/Geometry[123]/LayerElementUV"
+	Path string
+	// ObjectID is the ID of the object that failed to parse, 0 when the error isn't about an object
+	ObjectID uint64
+	// Err is the cause
+	Err error
+}
+
+// Error describes the error, its path and offset
+func (e *ParseError) Error() string {
+	s := "fbx: "
+	if e.Path != "" {
+		s += e.Path + ": "
+	}
+	s += e.Err.Error()
+	if e.Offset != 0 {
+		s += " (at offset " + strconv.FormatInt(e.Offset, 10) + ")"
+	}
+	return s
+}
+
+// Unwrap returns the cause
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// pathSegment names an element in a ParseError's path, with its ID when it has one
+func pathSegment(elem *Element) string {
+	if elem == nil || elem.ID == nil {
+		return ""
+	}
+	name := elem.ID.String()
+	if prop := elem.getProperty(0); isLong(prop) {
+		name += "[" + strconv.FormatUint(prop.value.touint64(), 10) + "]"
+	}
+	return name
+}
+
+// elementError wraps err in a ParseError about elem, or prepends elem to the path of err when it
+// already is one. Context errors are returned as they are.
+func elementError(elem *Element, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	pe, ok := err.(*ParseError)
+	if !ok {
+		pe = &ParseError{Err: err}
+	}
+	if pe.Offset == 0 && elem != nil {
+		pe.Offset = elem.offset
+	}
+	if segment := pathSegment(elem); segment != "" {
+		pe.Path = strings.TrimSuffix(segment+"/"+pe.Path, "/")
+	}
+	return pe
+}
+
+// objectError is an elementError about the object with the given ID inside objects
+func objectError(objects, elem *Element, id uint64, err error) error {
+	err = elementError(objects, elementError(elem, err))
+	if pe, ok := err.(*ParseError); ok && pe.ObjectID == 0 {
+		pe.ObjectID = id
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package ofbx
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrorMessage(t *testing.T) {
+	err := &ParseError{Offset: 120, Path: "Objects/Geometry[5]", ObjectID: 5, Err: io.ErrUnexpectedEOF}
+	assert.Equal(t, "fbx: Objects/Geometry[5]: unexpected EOF (at offset 120)", err.Error())
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.Equal(t, "fbx: invalid", (&ParseError{Err: errors.New("invalid")}).Error())
+
+	elem := newTestElement("LayerElementUV", []*Property{newIntegerProperty(0)})
+	elem.offset = 300
+	wrapped := elementError(newTestElement("Geometry", []*Property{newLongProperty(5)}), elementError(elem, io.EOF))
+	var pe *ParseError
+	require.True(t, errors.As(wrapped, &pe))
+	assert.Equal(t, "Geometry[5]/LayerElementUV", pe.Path)
+	assert.Equal(t, int64(300), pe.Offset)
+
+	assert.Equal(t, context.Canceled, elementError(elem, context.Canceled))
+	assert.Nil(t, elementError(elem, nil))
+}
+
+func TestParseErrorTruncatedFile(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/cube.fbx")
+	require.NoError(t, err)
+	_, err = Load(bytes.NewReader(data[:len(data)/2]))
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe), "%v", err)
+	assert.NotEmpty(t, pe.Path)
+	assert.Greater(t, pe.Offset, int64(0))
+	assert.LessOrEqual(t, pe.Offset, int64(len(data)/2))
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF), "%v", err)
+
+	_, err = Load(bytes.NewReader([]byte("; FBX 7.4.0 project file")))
+	assert.True(t, errors.As(err, &pe))
+}
+
+func TestParseErrorObjectPath(t *testing.T) {
+	root := newTestRoot([]*Element{
+		newTriangleGeometry(5, newTestElement("LayerElementUV", []*Property{newIntegerProperty(0)},
+			newTestElement("MappingInformationType", []*Property{newStringProperty("Sideways")}),
+			newTestElement("UV", []*Property{newDoubleArrayProperty(0, 0)}),
+		)),
+	})
+	_, err := parseObjects(context.Background(), root, &Scene{ObjectMap: make(map[uint64]Obj)})
+	var pe *ParseError
+	require.True(t, errors.As(err, &pe), "%v", err)
+	assert.Equal(t, "Objects/Geometry[5]/LayerElementUV", pe.Path)
+	assert.Equal(t, uint64(5), pe.ObjectID)
+
+	root = newTestRoot(nil, newTestElement("C", []*Property{newStringProperty("OO")}))
+	_, err = parseConnection(root, &Scene{})
+	require.True(t, errors.As(err, &pe), "%v", err)
+	assert.Equal(t, "Connections/C", pe.Path)
+	assert.Zero(t, pe.ObjectID)
+}
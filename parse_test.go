@@ -2,11 +2,13 @@ package ofbx
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"testing"
 
 	"github.com/oakmound/oak/v2/alg/floatgeom"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestParseTemplates(t *testing.T) {
@@ -409,9 +411,65 @@ func TestParseMaterialDefaultValues(t *testing.T) {
 
 	material := parseMaterial(scene, element)
 	assert.NotNil(t, material)
-	assert.Equal(t, float32(1.0), material.DiffuseColor.R)
-	assert.Equal(t, float32(1.0), material.DiffuseColor.G)
-	assert.Equal(t, float32(1.0), material.DiffuseColor.B)
+	// Without a template, the FBX SDK defaults apply
+	assert.Equal(t, Color{0.8, 0.8, 0.8}, material.DiffuseColor)
+	assert.Equal(t, 1.0, material.DiffuseFactor)
+	assert.Equal(t, 1.0, material.Opacity)
+	assert.Equal(t, ShadingPhong, material.ShadingModel)
+	assert.Equal(t, 0.0, material.Transparency())
+}
+
+func TestParseMaterialTemplate(t *testing.T) {
+	root := newTestElement("", nil,
+		newTestElement("Definitions", nil,
+			newTestElement("ObjectType", []*Property{newStringProperty("Material")},
+				newTestElement("PropertyTemplate", []*Property{newStringProperty("FbxSurfaceLambert")},
+					newTestElement("Properties70", nil,
+						newColorP70("DiffuseColor", 0.5, 0.5, 0.5),
+						newColorP70("TransparentColor", 1, 1, 1),
+						newP70("TransparencyFactor", "Number", newDoubleProperty(0.25)),
+						newP70("BumpFactor", "double", newDoubleProperty(0.5)),
+					),
+				),
+			),
+		),
+	)
+	scene := &Scene{templates: ParseTemplates(root)}
+	require.Contains(t, scene.templates, "MaterialFbxSurfaceLambert")
+
+	element := newMaterialElement(1,
+		newColorP70("DiffuseColor", 1, 0, 0),
+		newP70("DisplacementFactor", "double", newDoubleProperty(2)),
+		newP70("Opacity", "double", newDoubleProperty(0.1)),
+	)
+	element.Children = append(element.Children,
+		newTestElement("ShadingModel", []*Property{newStringProperty("Lambert")}),
+		newTestElement("MultiLayer", []*Property{newIntegerProperty(1)}),
+	)
+	material := parseMaterial(scene, element)
+	assert.Equal(t, ShadingLambert, material.ShadingModel)
+	assert.True(t, material.MultiLayer)
+	assert.Equal(t, Color{1, 0, 0}, material.DiffuseColor)
+	assert.Equal(t, 0.5, material.BumpFactor)
+	assert.Equal(t, 2.0, material.DisplacementFactor)
+	assert.Equal(t, 0.25, material.TransparencyFactor)
+	// TransparentColor and TransparencyFactor win over the legacy Opacity
+	assert.Equal(t, 0.25, material.Transparency())
+
+	material.TransparencyFactor = 0
+	assert.InDelta(t, 0.9, material.Transparency(), 1e-9)
+}
+
+func TestMaterialTransparencyFromFile(t *testing.T) {
+	scene := loadTestScene(t, "testdata/jyj.FBX")
+	transparent := 0
+	for _, obj := range scene.ObjectMap {
+		if mat, ok := obj.(*Material); ok && mat.Transparency() > 0 {
+			transparent++
+			assert.InDelta(t, 1-mat.Opacity, mat.Transparency(), 1e-6)
+		}
+	}
+	assert.Equal(t, 1, transparent)
 }
 
 func TestParseAnimationCurve(t *testing.T) {
@@ -588,7 +646,7 @@ func TestParseObjectsEmpty(t *testing.T) {
 		},
 	} // 包含空Objects节点的根元素
 
-	success, err := parseObjects(root, scene)
+	success, err := parseObjects(context.Background(), root, scene)
 	assert.NoError(t, err)
 	assert.True(t, success)
 	assert.NotNil(t, scene.RootNode)
@@ -598,18 +656,21 @@ func TestParseObjectsEmpty(t *testing.T) {
 func TestParseArrayRawIntEnd(t *testing.T) {
 	// 测试32位整数
 	data := []byte{1, 0, 0, 0, 2, 0, 0, 0} // [1, 2] in little-endian int32
-	result := parseArrayRawIntEnd(bytes.NewReader(data), 2, 4)
+	result, err := parseArrayRawIntEnd(bytes.NewReader(data), 2, 4)
+	require.NoError(t, err)
 	assert.Equal(t, []int{1, 2}, result)
 
 	// 测试64位整数
 	data = []byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0} // [1, 2] in little-endian int64
-	result = parseArrayRawIntEnd(bytes.NewReader(data), 2, 8)
+	result, err = parseArrayRawIntEnd(bytes.NewReader(data), 2, 8)
+	require.NoError(t, err)
 	assert.Equal(t, []int{1, 2}, result)
 }
 
 func TestParseArrayRawFloat64End(t *testing.T) {
 	data := []byte{0, 0, 0, 0, 0, 0, 240, 63, 0, 0, 0, 0, 0, 0, 0, 64} // [1.0, 2.0] in little-endian float64
-	result := parseArrayRawFloat64End(bytes.NewReader(data), 2, 8)
+	result, err := parseArrayRawFloat64End(bytes.NewReader(data), 2, 8)
+	require.NoError(t, err)
 	assert.Len(t, result, 2)
 	assert.InDelta(t, 1.0, result[0], 0.001)
 	assert.InDelta(t, 2.0, result[1], 0.001)
@@ -617,7 +678,8 @@ func TestParseArrayRawFloat64End(t *testing.T) {
 
 func TestParseArrayRawFloat32End(t *testing.T) {
 	data := []byte{0, 0, 128, 63, 0, 0, 0, 64} // [1.0, 2.0] in little-endian float32
-	result := parseArrayRawFloat32End(bytes.NewReader(data), 2, 4)
+	result, err := parseArrayRawFloat32End(bytes.NewReader(data), 2, 4)
+	require.NoError(t, err)
 	assert.Len(t, result, 2)
 	assert.InDelta(t, 1.0, result[0], 0.001)
 	assert.InDelta(t, 2.0, result[1], 0.001)
@@ -625,10 +687,16 @@ func TestParseArrayRawFloat32End(t *testing.T) {
 
 func TestParseArrayRawInt64End(t *testing.T) {
 	data := []byte{1, 0, 0, 0, 2, 0, 0, 0} // [1, 2] in little-endian int32
-	result := parseArrayRawInt64End(bytes.NewReader(data), 2, 4)
+	result, err := parseArrayRawInt64End(bytes.NewReader(data), 2, 4)
+	require.NoError(t, err)
 	assert.Equal(t, []int64{1, 2}, result)
 
 	data = []byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0} // [1, 2] in little-endian int64
-	result = parseArrayRawInt64End(bytes.NewReader(data), 2, 8)
+	result, err = parseArrayRawInt64End(bytes.NewReader(data), 2, 8)
+	require.NoError(t, err)
 	assert.Equal(t, []int64{1, 2}, result)
+
+	// Short data is an error instead of zeros
+	_, err = parseArrayRawInt64End(bytes.NewReader(data[:12]), 2, 8)
+	assert.Error(t, err)
 }
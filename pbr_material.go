@@ -0,0 +1,249 @@
+package ofbx
+
+import "math"
+
+// PBRWorkflow tells which material model a PBRMaterial was read from
+type PBRWorkflow int
+
+// PBRWorkflow options
+const (
+	// PBRFromPhong means the values were converted from the legacy Lambert/Phong properties
+	PBRFromPhong PBRWorkflow = iota
+	// PBRStingray is Maya's Stingray PBS shader
+	PBRStingray PBRWorkflow = iota
+	// PBRArnold is Arnold's aiStandardSurface, as exported by Maya
+	PBRArnold PBRWorkflow = iota
+	// PBRPhysical is 3ds Max's Physical Material
+	PBRPhysical PBRWorkflow = iota
+)
+
+func (w PBRWorkflow) String() string {
+	switch w {
+	case PBRFromPhong:
+		return "phong"
+	case PBRStingray:
+		return "stingray"
+	case PBRArnold:
+		return "arnold"
+	case PBRPhysical:
+		return "physical"
+	}
+	return ""
+}
+
+// PBRMaterial is a metallic-roughness view of a Material. Factors already include
+// their weights, so BaseColor and Emissive can be used as is.
+type PBRMaterial struct {
+	Material *Material
+	Workflow PBRWorkflow
+
+	BaseColor        Color
+	BaseColorTexture *Texture
+	Metallic         float64
+	MetallicTexture  *Texture
+	Roughness        float64
+	RoughnessTexture *Texture
+	Emissive         Color
+	EmissiveTexture  *Texture
+	// NormalTexture may be a bump map when the material only has one of those
+	NormalTexture    *Texture
+	OcclusionTexture *Texture
+	Opacity          float64
+	OpacityTexture   *Texture
+}
+
+// dielectricSpecular is the reflectance at normal incidence of non metals
+const dielectricSpecular = 0.04
+
+// PBR interprets the material as metallic-roughness, reading the PBR shader properties when the
+// material has them and converting the Phong ones otherwise
+func (m *Material) PBR() *PBRMaterial {
+	p := &PBRMaterial{Material: m, Opacity: 1}
+	switch {
+	case m.hasProperty("Maya|base_color", "Maya|TEX_color_map"):
+		p.readStingray()
+	case m.hasProperty("Maya|baseColor", "Maya|metalness"):
+		p.readArnold()
+	case m.hasProperty("3dsMax|Parameters|base_color", "3dsMax|Parameters|metalness"):
+		p.readPhysical()
+	default:
+		p.readPhong()
+	}
+	return p
+}
+
+func (m *Material) hasProperty(names ...string) bool {
+	for _, name := range names {
+		if resolveProperty(m, name) != nil || len(m.Textures[name]) > 0 || len(m.LayeredTextures[name]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Material) number(name string, defaultVal float64) float64 {
+	if v, ok := resolveNumberProperty(m, name); ok {
+		return v
+	}
+	return defaultVal
+}
+
+func (m *Material) color(name string, defaultVal Color) Color {
+	if c, ok := resolveColorProperty(m, name); ok {
+		return c
+	}
+	return defaultVal
+}
+
+func (p *PBRMaterial) readStingray() {
+	m := p.Material
+	p.Workflow = PBRStingray
+	// Stingray keeps its maps connected but switches them with use_*_map
+	texture := func(name, use string) *Texture {
+		if m.number("Maya|"+use, 1) == 0 {
+			return nil
+		}
+		return m.PropertyTexture("Maya|" + name)
+	}
+	p.BaseColor = m.color("Maya|base_color", Color{1, 1, 1})
+	p.BaseColorTexture = texture("TEX_color_map", "use_color_map")
+	p.Metallic = m.number("Maya|metallic", 0)
+	p.MetallicTexture = texture("TEX_metallic_map", "use_metallic_map")
+	p.Roughness = m.number("Maya|roughness", 0.5)
+	p.RoughnessTexture = texture("TEX_roughness_map", "use_roughness_map")
+	p.Emissive = scaleColor(m.color("Maya|emissive", Color{}), m.number("Maya|emissive_intensity", 1))
+	p.EmissiveTexture = texture("TEX_emissive_map", "use_emissive_map")
+	p.NormalTexture = texture("TEX_normal_map", "use_normal_map")
+	p.OcclusionTexture = texture("TEX_ao_map", "use_ao_map")
+	p.Opacity = m.number("Maya|opacity", 1)
+	p.OpacityTexture = m.PropertyTexture("Maya|TEX_opacity_map")
+}
+
+func (p *PBRMaterial) readArnold() {
+	m := p.Material
+	p.Workflow = PBRArnold
+	p.BaseColor = scaleColor(m.color("Maya|baseColor", Color{0.8, 0.8, 0.8}), m.number("Maya|base", 1))
+	p.BaseColorTexture = m.PropertyTexture("Maya|baseColor")
+	p.Metallic = m.number("Maya|metalness", 0)
+	p.MetallicTexture = m.PropertyTexture("Maya|metalness")
+	p.Roughness = m.number("Maya|specularRoughness", 0.2)
+	p.RoughnessTexture = m.PropertyTexture("Maya|specularRoughness")
+	p.Emissive = scaleColor(m.color("Maya|emissionColor", Color{1, 1, 1}), m.number("Maya|emission", 0))
+	p.EmissiveTexture = m.PropertyTexture("Maya|emissionColor")
+	p.NormalTexture = m.PropertyTexture("Maya|normalCamera")
+	// Arnold's opacity is a color, which glTF like pipelines can only use as a scalar
+	p.Opacity = colorAverage(m.color("Maya|opacity", Color{1, 1, 1}))
+	p.OpacityTexture = m.PropertyTexture("Maya|opacity")
+}
+
+func (p *PBRMaterial) readPhysical() {
+	m := p.Material
+	p.Workflow = PBRPhysical
+	const prefix = "3dsMax|Parameters|"
+	p.BaseColor = scaleColor(m.color(prefix+"base_color", Color{0.5, 0.5, 0.5}), m.number(prefix+"base_weight", 1))
+	p.BaseColorTexture = m.PropertyTexture(prefix + "base_color_map")
+	p.Metallic = m.number(prefix+"metalness", 0)
+	p.MetallicTexture = m.PropertyTexture(prefix + "metalness_map")
+	p.Roughness = m.number(prefix+"roughness", 0)
+	// roughness_inv means the value and its map are glossiness
+	if m.number(prefix+"roughness_inv", 0) != 0 {
+		p.Roughness = 1 - p.Roughness
+	}
+	p.RoughnessTexture = m.PropertyTexture(prefix + "roughness_map")
+	p.Emissive = scaleColor(m.color(prefix+"emit_color", Color{1, 1, 1}), m.number(prefix+"emission", 0))
+	p.EmissiveTexture = m.PropertyTexture(prefix + "emit_color_map")
+	p.NormalTexture = m.PropertyTexture(prefix + "bump_map")
+	p.Opacity = 1 - m.number(prefix+"transparency", 0)
+	p.OpacityTexture = m.PropertyTexture(prefix + "transparency_map")
+	if p.OpacityTexture == nil {
+		p.OpacityTexture = m.PropertyTexture(prefix + "cutout_map")
+	}
+}
+
+// readPhong converts the Lambert/Phong properties the way the glTF specular-glossiness converter does:
+// metallic is solved from how much brighter the specular is than a dielectric's, and the base color blends
+// diffuse and specular by it. Roughness comes from the shininess exponent's Blinn-Phong equivalent.
+func (p *PBRMaterial) readPhong() {
+	m := p.Material
+	p.Workflow = PBRFromPhong
+	diffuse := scaleColor(m.DiffuseColor, m.DiffuseFactor)
+	specular := Color{}
+	if m.ShadingModel != ShadingLambert {
+		specular = scaleColor(m.SpecularColor, m.SpecularFactor)
+	}
+	oneMinusSpecularStrength := 1 - float64(maxChannel(specular))
+	p.Metallic = solveMetallic(perceivedBrightness(diffuse), perceivedBrightness(specular), oneMinusSpecularStrength)
+	if perceivedBrightness(specular) < dielectricSpecular {
+		// Lambert, or too dull to tell: the diffuse is the albedo
+		p.BaseColor = Color{R: clamp01(diffuse.R), G: clamp01(diffuse.G), B: clamp01(diffuse.B)}
+	} else {
+		p.BaseColor = phongBaseColor(diffuse, specular, oneMinusSpecularStrength, p.Metallic)
+	}
+	p.BaseColorTexture = m.GetTexture(DIFFUSE)
+
+	p.Roughness = math.Sqrt(2 / (math.Max(m.ShininessExponent, 0) + 2))
+
+	p.Emissive = scaleColor(m.EmissiveColor, m.EmissiveFactor)
+	p.EmissiveTexture = m.GetTexture(EMISSIVE)
+	p.NormalTexture = m.PropertyTexture("NormalMap")
+	if p.NormalTexture == nil {
+		p.NormalTexture = m.PropertyTexture("Bump")
+	}
+
+	p.Opacity = 1 - m.Transparency()
+	p.OpacityTexture = m.GetTexture(TRANSPARENT)
+}
+
+// phongBaseColor blends the albedo implied by the diffuse with the one implied by the specular, by metallic
+func phongBaseColor(diffuse, specular Color, oneMinusSpecularStrength, metallic float64) Color {
+	fromDiffuse := scaleColor(diffuse, oneMinusSpecularStrength/(1-dielectricSpecular)/math.Max(1-metallic, 1e-4))
+	fromSpecular := Color{
+		R: float32((float64(specular.R) - dielectricSpecular*(1-metallic)) / math.Max(metallic, 1e-4)),
+		G: float32((float64(specular.G) - dielectricSpecular*(1-metallic)) / math.Max(metallic, 1e-4)),
+		B: float32((float64(specular.B) - dielectricSpecular*(1-metallic)) / math.Max(metallic, 1e-4)),
+	}
+	t := float32(metallic * metallic)
+	return Color{
+		R: clamp01(fromDiffuse.R + (fromSpecular.R-fromDiffuse.R)*t),
+		G: clamp01(fromDiffuse.G + (fromSpecular.G-fromDiffuse.G)*t),
+		B: clamp01(fromDiffuse.B + (fromSpecular.B-fromDiffuse.B)*t),
+	}
+}
+
+func solveMetallic(diffuse, specular, oneMinusSpecularStrength float64) float64 {
+	if specular < dielectricSpecular {
+		return 0
+	}
+	a := dielectricSpecular
+	b := diffuse*oneMinusSpecularStrength/(1-dielectricSpecular) + specular - 2*dielectricSpecular
+	c := dielectricSpecular - specular
+	d := math.Max(b*b-4*a*c, 0)
+	return math.Min(math.Max((-b+math.Sqrt(d))/(2*a), 0), 1)
+}
+
+func perceivedBrightness(c Color) float64 {
+	r, g, b := float64(c.R), float64(c.G), float64(c.B)
+	return math.Sqrt(0.299*r*r + 0.587*g*g + 0.114*b*b)
+}
+
+func maxChannel(c Color) float32 {
+	return float32(math.Max(float64(c.R), math.Max(float64(c.G), float64(c.B))))
+}
+
+func colorAverage(c Color) float64 {
+	return (float64(c.R) + float64(c.G) + float64(c.B)) / 3
+}
+
+func scaleColor(c Color, f float64) Color {
+	return Color{R: float32(float64(c.R) * f), G: float32(float64(c.G) * f), B: float32(float64(c.B) * f)}
+}
+
+func clamp01(f float32) float32 {
+	if f < 0 {
+		return 0
+	}
+	if f > 1 {
+		return 1
+	}
+	return f
+}
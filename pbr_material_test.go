@@ -0,0 +1,137 @@
+package ofbx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertColorInDelta(t *testing.T, expected, actual Color) {
+	t.Helper()
+	assert.InDelta(t, expected.R, actual.R, 1e-4)
+	assert.InDelta(t, expected.G, actual.G, 1e-4)
+	assert.InDelta(t, expected.B, actual.B, 1e-4)
+}
+
+func TestPBRStingray(t *testing.T) {
+	scene := loadTestElements(t, []*Element{
+		newMaterialElement(1,
+			newColorP70("Maya|base_color", 0.2, 0.4, 0.6),
+			newP70("Maya|metallic", "Float", newDoubleProperty(1)),
+			newP70("Maya|roughness", "Float", newDoubleProperty(0.3)),
+			newP70("Maya|use_color_map", "Float", newDoubleProperty(1)),
+			newP70("Maya|use_normal_map", "Float", newDoubleProperty(0)),
+			newColorP70("Maya|emissive", 1, 0.5, 0),
+			newP70("Maya|emissive_intensity", "Float", newDoubleProperty(2)),
+		),
+		newTextureElement(2),
+		newTextureElement(3),
+		newTextureElement(4),
+	},
+		newConnection("OP", 2, 1, "Maya|TEX_color_map"),
+		newConnection("OP", 3, 1, "Maya|TEX_normal_map"),
+		newConnection("OP", 4, 1, "Maya|TEX_ao_map"),
+	)
+	pbr := scene.ObjectMap[1].(*Material).PBR()
+	assert.Equal(t, PBRStingray, pbr.Workflow)
+	assertColorInDelta(t, Color{0.2, 0.4, 0.6}, pbr.BaseColor)
+	assert.Equal(t, 1.0, pbr.Metallic)
+	assert.Equal(t, 0.3, pbr.Roughness)
+	assertColorInDelta(t, Color{2, 1, 0}, pbr.Emissive)
+	assert.Same(t, scene.ObjectMap[2], pbr.BaseColorTexture)
+	// Switched off by use_normal_map
+	assert.Nil(t, pbr.NormalTexture)
+	// No use_ao_map flag means the map is used
+	assert.Same(t, scene.ObjectMap[4], pbr.OcclusionTexture)
+	assert.Equal(t, 1.0, pbr.Opacity)
+}
+
+func TestPBRArnold(t *testing.T) {
+	scene := loadTestElements(t, []*Element{
+		newMaterialElement(1,
+			newColorP70("Maya|baseColor", 1, 1, 1),
+			newP70("Maya|base", "Float", newDoubleProperty(0.5)),
+			newP70("Maya|metalness", "Float", newDoubleProperty(0.25)),
+			newColorP70("Maya|opacity", 0.5, 0.5, 0.5),
+		),
+		newTextureElement(2),
+	}, newConnection("OP", 2, 1, "Maya|normalCamera"))
+	pbr := scene.ObjectMap[1].(*Material).PBR()
+	assert.Equal(t, PBRArnold, pbr.Workflow)
+	assertColorInDelta(t, Color{0.5, 0.5, 0.5}, pbr.BaseColor)
+	assert.Equal(t, 0.25, pbr.Metallic)
+	assert.Equal(t, 0.2, pbr.Roughness)
+	assertColorInDelta(t, Color{}, pbr.Emissive)
+	assert.Same(t, scene.ObjectMap[2], pbr.NormalTexture)
+	assert.InDelta(t, 0.5, pbr.Opacity, 1e-6)
+}
+
+func TestPBRPhysical(t *testing.T) {
+	scene := loadTestElements(t, []*Element{
+		newMaterialElement(1,
+			newP70("3dsMax|Parameters|base_color", "RGBA", newDoubleProperty(0.8), newDoubleProperty(0.1), newDoubleProperty(0.1), newDoubleProperty(1)),
+			newP70("3dsMax|Parameters|roughness", "Float", newDoubleProperty(0.7)),
+			newP70("3dsMax|Parameters|roughness_inv", "Bool", newIntegerProperty(1)),
+			newP70("3dsMax|Parameters|transparency", "Float", newDoubleProperty(0.25)),
+		),
+		newTextureElement(2),
+	}, newConnection("OP", 2, 1, "3dsMax|Parameters|roughness_map"))
+	pbr := scene.ObjectMap[1].(*Material).PBR()
+	assert.Equal(t, PBRPhysical, pbr.Workflow)
+	assertColorInDelta(t, Color{0.8, 0.1, 0.1}, pbr.BaseColor)
+	assert.InDelta(t, 0.3, pbr.Roughness, 1e-6)
+	assert.Same(t, scene.ObjectMap[2], pbr.RoughnessTexture)
+	assert.Equal(t, 0.75, pbr.Opacity)
+}
+
+func TestPBRFromPhong(t *testing.T) {
+	lambertElement := newMaterialElement(1,
+		newColorP70("DiffuseColor", 0.5, 0.25, 1),
+		newColorP70("TransparentColor", 1, 1, 1),
+		newP70("TransparencyFactor", "Number", newDoubleProperty(0.4)),
+	)
+	lambertElement.Children = append(lambertElement.Children, newTestElement("ShadingModel", []*Property{newStringProperty("lambert")}))
+	scene := loadTestElements(t, []*Element{
+		lambertElement,
+		// Gold like Phong: no diffuse, bright colored specular
+		newMaterialElement(2,
+			newColorP70("DiffuseColor", 0, 0, 0),
+			newColorP70("SpecularColor", 1, 0.8, 0.3),
+			newP70("ShininessExponent", "Number", newDoubleProperty(98)),
+		),
+		newTextureElement(3),
+	}, newConnection("OP", 3, 1, "Bump"))
+
+	lambert := scene.ObjectMap[1].(*Material).PBR()
+	assert.Equal(t, PBRFromPhong, lambert.Workflow)
+	assert.Equal(t, 0.0, lambert.Metallic)
+	assertColorInDelta(t, Color{0.5, 0.25, 1}, lambert.BaseColor)
+	assert.InDelta(t, math.Sqrt(2.0/22), lambert.Roughness, 1e-9)
+	assert.InDelta(t, 0.6, lambert.Opacity, 1e-9)
+	assert.Same(t, scene.ObjectMap[3], lambert.NormalTexture)
+
+	gold := scene.ObjectMap[2].(*Material).PBR()
+	assert.InDelta(t, 1, gold.Metallic, 1e-6)
+	assertColorInDelta(t, Color{1, 0.8, 0.3}, gold.BaseColor)
+	assert.InDelta(t, math.Sqrt(0.02), gold.Roughness, 1e-9)
+}
+
+func TestPBRFromFile(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	found := false
+	for _, obj := range scene.ObjectMap {
+		mat, ok := obj.(*Material)
+		if !ok {
+			continue
+		}
+		found = true
+		pbr := mat.PBR()
+		require.Equal(t, PBRFromPhong, pbr.Workflow)
+		assert.Same(t, mat.GetTexture(DIFFUSE), pbr.BaseColorTexture)
+		assert.True(t, pbr.Roughness > 0 && pbr.Roughness <= 1)
+		assert.True(t, pbr.Metallic >= 0 && pbr.Metallic <= 1)
+	}
+	assert.True(t, found)
+}
@@ -20,7 +20,7 @@ func TestPrintScene(t *testing.T) {
 		log.Fatal(err)
 	}
 	fmt.Println(scene)
-	fmt.Println(scene.Meshes[0].Materials[0].Textures[0].relativeFilename)
+	fmt.Println(scene.Meshes[0].Materials[0].GetTexture(DIFFUSE).relativeFilename)
 }
 
 type Model struct {
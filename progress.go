@@ -0,0 +1,38 @@
+package ofbx
+
+import "io"
+
+// ProgressFunc is called while a file is read with the number of bytes read so far
+// and the size of the file, which is -1 when it isn't known
+type ProgressFunc func(read, total int64)
+
+// readerSize returns how many bytes are left in r, or -1 when r can't tell. A Size method is taken
+// to count from where seeking starts, as with bytes.Reader and io.SectionReader.
+func readerSize(r io.Reader) int64 {
+	seeker, canSeek := r.(io.Seeker)
+	if sizer, ok := r.(interface{ Size() int64 }); ok {
+		if !canSeek {
+			return sizer.Size()
+		}
+		cur, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		return sizer.Size() - cur
+	}
+	if canSeek {
+		cur, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := seeker.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := seeker.Seek(cur, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - cur
+	}
+	return -1
+}
@@ -1,8 +1,10 @@
 package ofbx
 
 import (
+	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 )
 
 // PropertyType is a mapping of letter to data type
@@ -93,6 +95,40 @@ func (p *Property) stringValue() string {
 	return "Error: Not a known property Type " + string(p.Type)
 }
 
+// toInt64 converts a scalar property of any numeric type, which makes enum and bool values readable
+// whichever way the exporter chose to store them
+func (p *Property) toInt64() int64 {
+	switch p.Type {
+	case BOOL:
+		if p.value.toBool() {
+			return 1
+		}
+		return 0
+	case INT16:
+		var b [2]byte
+		p.value.ReadAt(b[:], 0)
+		return int64(int16(binary.LittleEndian.Uint16(b[:])))
+	case LONG:
+		return p.value.toint64()
+	case FLOAT:
+		return int64(p.value.toFloat())
+	case DOUBLE:
+		return int64(p.value.toDouble())
+	}
+	return int64(p.value.toInt32())
+}
+
+// toFloat64 converts a scalar property of any numeric type
+func (p *Property) toFloat64() float64 {
+	switch p.Type {
+	case FLOAT:
+		return float64(p.value.toFloat())
+	case DOUBLE:
+		return p.value.toDouble()
+	}
+	return float64(p.toInt64())
+}
+
 // Size returns the current property type's size
 func (pt PropertyType) Size() int {
 	return propertyTypeSizes[pt]
@@ -210,3 +246,63 @@ func (p *Property) stringPrefix(prefix string) string {
 	// s += ", compressedLen=" + fmt.Sprintf("%d", p.compressedLength)
 	return s
 }
+
+func newStringProperty(s string) *Property {
+	return &Property{Type: STRING, value: NewDataView(s)}
+}
+
+func newDoubleProperty(f float64) *Property {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	return &Property{Type: DOUBLE, value: NewDataView(string(b[:]))}
+}
+
+func newIntegerProperty(i int32) *Property {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(i))
+	return &Property{Type: INTEGER, value: NewDataView(string(b[:]))}
+}
+
+func newLongProperty(i int64) *Property {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(i))
+	return &Property{Type: LONG, value: NewDataView(string(b[:]))}
+}
+
+func newLongArrayProperty(is []int64) *Property {
+	b := make([]byte, 8*len(is))
+	for i, v := range is {
+		binary.LittleEndian.PutUint64(b[i*8:], uint64(v))
+	}
+	return &Property{Type: ArrayLONG, Count: len(is), value: NewDataView(string(b))}
+}
+
+func newFloatArrayProperty(fs []float32) *Property {
+	b := make([]byte, 4*len(fs))
+	for i, f := range fs {
+		binary.LittleEndian.PutUint32(b[i*4:], math.Float32bits(f))
+	}
+	return &Property{Type: ArrayFLOAT, Count: len(fs), value: NewDataView(string(b))}
+}
+
+// addProperty70 appends a new P entry to the object's Properties70, creating the block if needed
+func addProperty70(obj Obj, name, typ, label, flags string, values ...*Property) *Element {
+	element := obj.Element()
+	elems := findChildren(element, "Properties70")
+	var props70 *Element
+	if len(elems) == 0 {
+		props70 = &Element{ID: NewDataView("Properties70")}
+		element.Children = append(element.Children, props70)
+	} else {
+		props70 = elems[0]
+	}
+	p := &Element{ID: NewDataView("P")}
+	p.Properties = append([]*Property{
+		newStringProperty(name),
+		newStringProperty(typ),
+		newStringProperty(label),
+		newStringProperty(flags),
+	}, values...)
+	props70.Children = append(props70.Children, p)
+	return p
+}
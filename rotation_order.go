@@ -145,8 +145,8 @@ func (o RotationOrder) rotationMatrix(euler floatgeom.Point3) Matrix {
 		te.m[6] = b * e
 		te.m[10] = bd*f + ac
 
-	case SphericXYZ:
-		// SphericXYZ目前不支持，当作EulerXYZ处理
+	default:
+		// SphericXYZ目前不支持，当作EulerXYZ处理，未知的值也一样
 		te.m[0] = c * e
 		te.m[4] = -c * f
 		te.m[8] = d
@@ -158,9 +158,6 @@ func (o RotationOrder) rotationMatrix(euler floatgeom.Point3) Matrix {
 		te.m[2] = b*f - a*e*d
 		te.m[6] = b*e + a*f*d
 		te.m[10] = a * c
-
-	default:
-		panic("Unsupported rotation order")
 	}
 
 	// 最后三列保持单位矩阵
@@ -170,3 +167,44 @@ func (o RotationOrder) rotationMatrix(euler floatgeom.Point3) Matrix {
 
 	return te
 }
+
+// axes returns the axis indices (0=X, 1=Y, 2=Z) in the order their rotations are multiplied
+func (o RotationOrder) axes() [3]int {
+	switch o {
+	case EulerXZY:
+		return [3]int{0, 2, 1}
+	case EulerYZX:
+		return [3]int{1, 2, 0}
+	case EulerYXZ:
+		return [3]int{1, 0, 2}
+	case EulerZXY:
+		return [3]int{2, 0, 1}
+	case EulerZYX:
+		return [3]int{2, 1, 0}
+	}
+	return [3]int{0, 1, 2}
+}
+
+// rotationOrderFromAxes is the inverse of RotationOrder.axes
+func rotationOrderFromAxes(axes [3]int) RotationOrder {
+	for _, o := range []RotationOrder{EulerXYZ, EulerXZY, EulerYZX, EulerYXZ, EulerZXY, EulerZYX} {
+		if o.axes() == axes {
+			return o
+		}
+	}
+	return EulerXYZ
+}
+
+// eulerXYZFromMatrix extracts EulerXYZ angles in degrees from the rotation part of m
+func eulerXYZFromMatrix(m Matrix) floatgeom.Point3 {
+	m13 := math.Max(-1, math.Min(1, m.m[8]))
+	var x, y, z float64
+	y = math.Asin(m13)
+	if math.Abs(m13) < 0.9999999 {
+		x = math.Atan2(-m.m[9], m.m[10])
+		z = math.Atan2(-m.m[4], m.m[0])
+	} else {
+		x = math.Atan2(m.m[6], m.m[5])
+	}
+	return floatgeom.Point3{x / alg.DegToRad, y / alg.DegToRad, z / alg.DegToRad}
+}
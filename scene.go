@@ -1,8 +1,10 @@
 package ofbx
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"sync"
 )
 
 // A Scene is an overarching FBX costruct containing objects and animations
@@ -14,8 +16,17 @@ type Scene struct {
 	ObjectMap       map[uint64]Obj
 	Meshes          []*Mesh
 	AnimationStacks []*AnimationStack
+	Videos          []*Video
 	Connections     []Connection
 	TakeInfos       []TakeInfo
+	Metadata        Metadata
+	// Warnings lists what a lenient load skipped or repaired
+	Warnings []Warning
+
+	options   LoadOptions
+	templates map[string]*Element
+	imagesMu  sync.Mutex
+	images    map[imageKey]*imageEntry
 }
 
 func (s *Scene) String() string {
@@ -82,15 +93,20 @@ func (s *Scene) GetTakeInfo(name string) *TakeInfo {
 
 // Load tries to load a scene
 func Load(r io.Reader) (*Scene, error) {
-	s := &Scene{}
-	s.ObjectMap = make(map[uint64]Obj)
-	root, err := tokenize(r)
 	// Todo: reimplement text
-	if err != nil {
-		return nil, err
-	}
+	return LoadWithOptions(r, LoadOptions{})
+}
 
+// newScene parses a scene from a tokenized file
+func newScene(ctx context.Context, root *Element, header Header, opts LoadOptions) (*Scene, error) {
+	s := &Scene{options: opts}
+	s.ObjectMap = make(map[uint64]Obj)
+	if header.Version < legacyVersion {
+		convertLegacy(root)
+	}
 	s.RootElement = root
+	s.templates = ParseTemplates(root)
+	s.Metadata = parseMetadata(root, header)
 
 	if ok, err := parseConnection(root, s); !ok {
 		return nil, err
@@ -98,18 +114,30 @@ func Load(r io.Reader) (*Scene, error) {
 	if ok, err := parseTakes(s); !ok {
 		return nil, err
 	}
-	if ok, err := parseObjects(root, s); !ok {
+	if ok, err := parseObjects(ctx, root, s); !ok {
 		return nil, err
 	}
 	parseGlobalSettings(root, s)
 	s.PostProcess() // 添加后处理调用
+	if err := opts.convert(s); err != nil {
+		return nil, err
+	}
+	if opts.DiscardElements {
+		s.RootElement = nil
+		s.templates = nil
+	}
 
 	return s, nil
 }
 
 // 在Scene结构体中添加
 func (s *Scene) PostProcess() {
+	if s.options.NoBakeGeometricTransform {
+		return
+	}
 	for _, m := range s.Meshes {
-		m.applyLocalTransform()
+		if m.Geometry != nil {
+			m.applyLocalTransform()
+		}
 	}
 }
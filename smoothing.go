@@ -0,0 +1,158 @@
+package ofbx
+
+import "strconv"
+
+// edgesFromPolygonVertices decodes the Edges array, where every edge is stored as the index in
+// PolygonVertexIndex of its first corner and runs to the next corner of the same polygon
+func edgesFromPolygonVertices(edges, origIndices []int) [][2]int {
+	decode := func(idx int) int {
+		if idx < 0 {
+			return -idx - 1
+		}
+		return idx
+	}
+	polygonStarts := make([]int, len(origIndices))
+	start := 0
+	for i, idx := range origIndices {
+		polygonStarts[i] = start
+		if idx < 0 {
+			start = i + 1
+		}
+	}
+
+	out := make([][2]int, len(edges))
+	for i, e := range edges {
+		if e < 0 || e >= len(origIndices) {
+			out[i] = [2]int{-1, -1}
+			continue
+		}
+		next := e + 1
+		if origIndices[e] < 0 {
+			next = polygonStarts[e]
+		}
+		if next >= len(origIndices) {
+			out[i] = [2]int{-1, -1}
+			continue
+		}
+		out[i] = [2]int{decode(origIndices[e]), decode(origIndices[next])}
+	}
+	return out
+}
+
+func edgeKey(a, b int) [2]int {
+	if a > b {
+		return [2]int{b, a}
+	}
+	return [2]int{a, b}
+}
+
+// smoothingNeighbours returns, for every polygon vertex, the polygon vertices of the same control
+// point it is smoothed with, itself included. Polygons only smooth across edges that aren't hard and
+// with polygons they share a smoothing group with, so two polygons that each share a group with a
+// third still don't smooth together. It returns nil when the geometry defines neither.
+func (g *Geometry) smoothingNeighbours() [][]int {
+	hasGroups := len(g.SmoothingGroups) != 0 && len(g.SmoothingGroups) >= len(g.Faces)
+	hasHardEdges := len(g.HardEdges) != 0 && len(g.HardEdges) == len(g.Edges)
+	if !hasGroups && !hasHardEdges {
+		return nil
+	}
+
+	corners := g.cornerControlPoints()
+	polygons := make([]int, 0, len(corners))
+	for poly, face := range g.Faces {
+		for range face {
+			polygons = append(polygons, poly)
+		}
+	}
+	sharesGroup := func(a, b int) bool {
+		return !hasGroups || g.SmoothingGroups[polygons[a]]&g.SmoothingGroups[polygons[b]] != 0
+	}
+
+	// Polygon vertices joined through soft edges around their control point
+	parent := sequence(len(corners))
+	find := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	join := func(a, b int) {
+		parent[find(a)] = find(b)
+	}
+	if hasHardEdges {
+		hard := make(map[[2]int]bool)
+		for i, e := range g.Edges {
+			if g.HardEdges[i] {
+				hard[edgeKey(e[0], e[1])] = true
+			}
+		}
+		// The polygon vertices at either end of every polygon side, lowest control point first
+		sides := make(map[[2]int][][2]int)
+		pv := 0
+		for _, face := range g.Faces {
+			for i := range face {
+				a, b := pv+i, pv+(i+1)%len(face)
+				if corners[a] > corners[b] {
+					a, b = b, a
+				}
+				key := edgeKey(corners[a], corners[b])
+				sides[key] = append(sides[key], [2]int{a, b})
+			}
+			pv += len(face)
+		}
+		for key, list := range sides {
+			if hard[key] {
+				continue
+			}
+			for i := range list {
+				for j := i + 1; j < len(list); j++ {
+					if sharesGroup(list[i][0], list[j][0]) {
+						join(list[i][0], list[j][0])
+						join(list[i][1], list[j][1])
+					}
+				}
+			}
+		}
+	}
+
+	shared := make(map[int][]int)
+	for pv, cp := range corners {
+		shared[cp] = append(shared[cp], pv)
+	}
+	neighbours := make([][]int, len(corners))
+	for pv, cp := range corners {
+		for _, other := range shared[cp] {
+			if (!hasHardEdges || find(other) == find(pv)) && sharesGroup(pv, other) {
+				neighbours[pv] = append(neighbours[pv], other)
+			}
+		}
+	}
+	return neighbours
+}
+
+// smoothingClasses returns, for every polygon vertex, an id shared by the polygon vertices that are
+// smoothed with the same ones. It returns nil when the geometry defines neither hard edges nor
+// smoothing groups.
+func (g *Geometry) smoothingClasses() []int {
+	neighbours := g.smoothingNeighbours()
+	if neighbours == nil {
+		return nil
+	}
+	ids := make(map[string]int)
+	classes := make([]int, len(neighbours))
+	var key []byte
+	for pv, list := range neighbours {
+		key = key[:0]
+		for _, other := range list {
+			key = strconv.AppendInt(append(key, ','), int64(other), 10)
+		}
+		id, ok := ids[string(key)]
+		if !ok {
+			id = len(ids)
+			ids[string(key)] = id
+		}
+		classes[pv] = id
+	}
+	return classes
+}
@@ -0,0 +1,104 @@
+package ofbx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEdgesFromPolygonVertices(t *testing.T) {
+	// A quad and a triangle sharing the edge 1-2
+	origIndices := []int{0, 1, 2, -4, 1, 4, -3}
+	edges := edgesFromPolygonVertices([]int{0, 1, 2, 3, 5, 6, 9}, origIndices)
+	assert.Equal(t, [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {4, 2}, {2, 1}, {-1, -1}}, edges)
+}
+
+func TestParseEdges(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	geom := scene.Meshes[0].Geometry
+	// 12 cube edges plus one diagonal per side
+	require.Len(t, geom.Edges, 18)
+	unique := make(map[[2]int]bool)
+	for _, e := range geom.Edges {
+		unique[edgeKey(e[0], e[1])] = true
+	}
+	assert.Len(t, unique, 18)
+	for _, face := range geom.Faces {
+		for i := range face {
+			assert.True(t, unique[edgeKey(face[i], face[(i+1)%len(face)])])
+		}
+	}
+}
+
+func foldedQuads() *Geometry {
+	geom := NewGeometry(&Scene{}, &Element{ID: NewDataView("Geometry")})
+	s, c := math.Sin(math.Pi/6), math.Cos(math.Pi/6)
+	geom.Vertices = []floatgeom.Point3{{0, 0, 0}, {0, 1, 0}, {-1, 1, 0}, {-1, 0, 0}, {c, 1, s}, {c, 0, s}}
+	geom.Faces = [][]int{{0, 1, 2, 3}, {0, 5, 4, 1}}
+	geom.Edges = [][2]int{{0, 1}, {1, 2}, {2, 3}, {3, 0}, {0, 5}, {5, 4}, {4, 1}}
+	return geom
+}
+
+func TestGenerateNormalsHardEdges(t *testing.T) {
+	geom := foldedQuads()
+	geom.HardEdges = []bool{false, false, false, false, false, false, false}
+	geom.GenerateNormals(0)
+	assertPointsInDelta(t, floatgeom.Point3{-math.Sin(math.Pi / 12), 0, math.Cos(math.Pi / 12)}, geom.Normals[0])
+
+	geom.HardEdges[0] = true
+	geom.GenerateNormals(180)
+	assertPointsInDelta(t, floatgeom.Point3{0, 0, 1}, geom.Normals[0])
+	assertPointsInDelta(t, floatgeom.Point3{-math.Sin(math.Pi / 6), 0, math.Cos(math.Pi / 6)}, geom.Normals[4])
+}
+
+func TestGenerateNormalsSmoothingGroups(t *testing.T) {
+	geom := foldedQuads()
+	geom.SmoothingGroups = []int{1, 2}
+	geom.GenerateNormals(180)
+	assertPointsInDelta(t, floatgeom.Point3{0, 0, 1}, geom.Normals[0])
+
+	geom.SmoothingGroups = []int{1, 3}
+	geom.GenerateNormals(0)
+	assertPointsInDelta(t, floatgeom.Point3{-math.Sin(math.Pi / 12), 0, math.Cos(math.Pi / 12)}, geom.Normals[0])
+}
+
+func TestBuildTriangleMeshSplitsSmoothing(t *testing.T) {
+	geom := NewGeometry(&Scene{}, &Element{ID: NewDataView("Geometry")})
+	geom.Vertices = []floatgeom.Point3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {2, 0, 0}, {2, 1, 0}}
+	geom.Faces = [][]int{{0, 1, 2, 3}, {1, 4, 5, 2}}
+	geom.GenerateNormals(0)
+	assert.Len(t, geom.BuildTriangleMesh(TriangleMeshOptions{}).Positions, 6)
+
+	// Coplanar polygons in different smoothing groups still get their own vertices
+	geom.SmoothingGroups = []int{1, 2}
+	assert.Len(t, geom.BuildTriangleMesh(TriangleMeshOptions{}).Positions, 8)
+	assert.Len(t, geom.BuildTriangleMesh(TriangleMeshOptions{NoNormals: true}).Positions, 6)
+}
+
+func TestGenerateNormalsOverlappingGroups(t *testing.T) {
+	// The three quads at a cube corner, facing +z, +x and +y, in groups A, A|B and B
+	geom := NewGeometry(&Scene{}, &Element{ID: NewDataView("Geometry")})
+	geom.Vertices = []floatgeom.Point3{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}, {1, 1, 0}, {0, 1, 1}, {1, 0, 1}}
+	geom.Faces = [][]int{{0, 1, 4, 2}, {0, 2, 5, 3}, {0, 3, 6, 1}}
+	geom.Edges = [][2]int{{0, 1}, {1, 4}, {4, 2}, {2, 0}, {2, 5}, {5, 3}, {3, 0}, {3, 6}, {6, 1}}
+	geom.SmoothingGroups = []int{1, 3, 2}
+
+	s := 1 / math.Sqrt2
+	c := 1 / math.Sqrt(3)
+	for _, hardEdges := range [][]bool{nil, make([]bool, len(geom.Edges))} {
+		geom.HardEdges = hardEdges
+		geom.GenerateNormals(0)
+		// A and B stay hard across their shared edge, and only A|B smooths with both
+		assertPointsInDelta(t, floatgeom.Point3{s, 0, s}, geom.Normals[0])
+		assertPointsInDelta(t, floatgeom.Point3{c, c, c}, geom.Normals[4])
+		assertPointsInDelta(t, floatgeom.Point3{s, s, 0}, geom.Normals[8])
+
+		classes := geom.smoothingClasses()
+		assert.NotEqual(t, classes[0], classes[4])
+		assert.NotEqual(t, classes[4], classes[8])
+		assert.NotEqual(t, classes[0], classes[8])
+	}
+}
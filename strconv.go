@@ -6,101 +6,55 @@ import (
 	"github.com/oakmound/oak/v2/alg/floatgeom"
 )
 
-func IntFromString(str, end string, val *int) string {
-	v, err := strconv.Atoi(str)
-	*val = v
-	if err != nil {
-		panic("Strconv Failed.")
-	}
+// skipField returns str after its first comma, looking at no more than len(end) bytes. Malformed
+// numbers don't stop the parsing functions, they read as zero.
+func skipField(str, end string) string {
 	iter := 0
-	for iter < len(end) && str[iter] != ',' {
+	for iter < len(end) && iter < len(str) && str[iter] != ',' {
 		iter++
 	}
-	if iter < len(end) {
+	if iter < len(end) && iter < len(str) {
 		iter++
 	}
 	return str[iter:]
 }
 
+func IntFromString(str, end string, val *int) string {
+	v, _ := strconv.Atoi(str)
+	*val = v
+	return skipField(str, end)
+}
+
 func Uint64FromString(str, end string, val *uint64) string {
-	v, err := strconv.ParseUint(str, 10, 64)
+	v, _ := strconv.ParseUint(str, 10, 64)
 	*val = v
-	if err != nil {
-		panic("Strconv Failed.")
-	}
-	iter := 0
-	for iter < len(end) && str[iter] != ',' {
-		iter++
-	}
-	if iter < len(end) {
-		iter++
-	}
-	return str[iter:]
+	return skipField(str, end)
 }
 
 func Int64FromString(str, end string, val *int64) string {
-	v, err := strconv.ParseInt(str, 10, 64)
+	v, _ := strconv.ParseInt(str, 10, 64)
 	*val = v
-	if err != nil {
-		panic("Strconv Failed.")
-	}
-	iter := 0
-	for iter < len(end) && str[iter] != ',' {
-		iter++
-	}
-	if iter < len(end) {
-		iter++
-	}
-	return str[iter:]
+	return skipField(str, end)
 }
 
 func DoubleFromString(str, end string, val *float64) string {
-	v, err := strconv.ParseFloat(str, 64)
+	v, _ := strconv.ParseFloat(str, 64)
 	*val = v
-	if err != nil {
-		panic("Strconv Failed.")
-	}
-	iter := 0
-	for iter < len(end) && str[iter] != ',' {
-		iter++
-	}
-	if iter < len(end) {
-		iter++
-	}
-	return str[iter:]
+	return skipField(str, end)
 }
 
 func FloatFromString(str, end string, val *float32) string {
-	v, err := strconv.ParseFloat(str, 32)
+	v, _ := strconv.ParseFloat(str, 32)
 	*val = float32(v)
-	if err != nil {
-		panic("Strconv Failed.")
-	}
-	iter := 0
-	for iter < len(end) && str[iter] != ',' {
-		iter++
-	}
-	if iter < len(end) {
-		iter++
-	}
-	return str[iter:]
+	return skipField(str, end)
 }
 
 func fromString(str, end string, val *float64, count int) string {
 	iter := 0
 	for i := 0; i < count; i++ {
-		v, err := strconv.ParseFloat(str[iter:], 64)
+		v, _ := strconv.ParseFloat(str[iter:], 64)
 		*val = v
-		if err != nil {
-			panic("Strconv Failed.")
-		}
-		iter = 0
-		for iter < len(end) && str[iter] != ',' {
-			iter++
-		}
-		if iter < len(end) {
-			iter++
-		}
+		iter = len(str) - len(skipField(str, end))
 
 		if iter == len(end) {
 			return str[iter:]
@@ -0,0 +1,52 @@
+package ofbx
+
+// Submesh is a range of a triangle mesh's indices that is drawn with a single material
+type Submesh struct {
+	// Slot is the index of the material in Mesh.Materials, or -1 for triangles whose slot is missing or
+	// out of range, which should be drawn with a default material
+	Slot     int
+	Material *Material
+	// IndexStart and IndexCount delimit the submesh in TriangleMesh.Indices
+	IndexStart, IndexCount int
+}
+
+// Submeshes triangulates the mesh's geometry and groups its triangles by material, so that each
+// Submesh can be drawn with one call. Submeshes come in slot order, followed by the default one,
+// and empty slots are left out. The triangle mesh's Materials are updated to match.
+func (m *Mesh) Submeshes(opts TriangleMeshOptions) (*TriangleMesh, []Submesh) {
+	if m.Geometry == nil {
+		return nil, nil
+	}
+	tm := m.Geometry.BuildTriangleMesh(opts)
+
+	// The last bucket collects the triangles without a usable material
+	buckets := make([][]int, len(m.Materials)+1)
+	for tri, slot := range tm.Materials {
+		if slot < 0 || slot >= len(m.Materials) || m.Materials[slot] == nil {
+			slot = len(m.Materials)
+		}
+		buckets[slot] = append(buckets[slot], tri)
+	}
+
+	indices := make([]uint32, 0, len(tm.Indices))
+	materials := make([]int, 0, len(tm.Materials))
+	submeshes := make([]Submesh, 0)
+	for slot, tris := range buckets {
+		if len(tris) == 0 {
+			continue
+		}
+		submesh := Submesh{Slot: -1, IndexStart: len(indices), IndexCount: 3 * len(tris)}
+		if slot < len(m.Materials) {
+			submesh.Slot = slot
+			submesh.Material = m.Materials[slot]
+		}
+		for _, tri := range tris {
+			indices = append(indices, tm.Indices[3*tri:3*tri+3]...)
+			materials = append(materials, submesh.Slot)
+		}
+		submeshes = append(submeshes, submesh)
+	}
+	tm.Indices = indices
+	tm.Materials = materials
+	return tm, submeshes
+}
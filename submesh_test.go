@@ -0,0 +1,55 @@
+package ofbx
+
+import (
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmeshes(t *testing.T) {
+	scene := &Scene{}
+	mesh := NewMesh(scene, &Element{ID: NewDataView("Model")})
+	mesh.Materials = []*Material{
+		NewMaterial(scene, &Element{ID: NewDataView("Material")}),
+		NewMaterial(scene, &Element{ID: NewDataView("Material")}),
+	}
+	geom := NewGeometry(scene, &Element{ID: NewDataView("Geometry")})
+	geom.Vertices = []floatgeom.Point3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {2, 0, 0}}
+	geom.Faces = [][]int{{0, 1, 2, 3}, {1, 4, 2}, {0, 2, 3}}
+	// A broken export pointing the last polygon at a material that doesn't exist
+	geom.polygonMaterials = []int{1, 0, 7}
+	mesh.Geometry = geom
+
+	tm, submeshes := mesh.Submeshes(TriangleMeshOptions{})
+	require.Len(t, submeshes, 3)
+	assert.Equal(t, Submesh{Slot: 0, Material: mesh.Materials[0], IndexStart: 0, IndexCount: 3}, submeshes[0])
+	assert.Equal(t, Submesh{Slot: 1, Material: mesh.Materials[1], IndexStart: 3, IndexCount: 6}, submeshes[1])
+	assert.Equal(t, Submesh{Slot: -1, IndexStart: 9, IndexCount: 3}, submeshes[2])
+	assert.Equal(t, []int{0, 1, 1, -1}, tm.Materials)
+	for i := submeshes[0].IndexStart; i < submeshes[0].IndexStart+submeshes[0].IndexCount; i++ {
+		assert.Contains(t, []int{1, 2, 4}, tm.ControlPoints[tm.Indices[i]])
+	}
+
+	mesh.Geometry = nil
+	tm, submeshes = mesh.Submeshes(TriangleMeshOptions{})
+	assert.Nil(t, tm)
+	assert.Nil(t, submeshes)
+}
+
+func TestSubmeshesScene(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	for _, mesh := range scene.Meshes {
+		tm, submeshes := mesh.Submeshes(TriangleMeshOptions{})
+		count := 0
+		for _, submesh := range submeshes {
+			assert.Equal(t, count, submesh.IndexStart)
+			count += submesh.IndexCount
+			if submesh.Slot >= 0 {
+				assert.Same(t, mesh.Materials[submesh.Slot], submesh.Material)
+			}
+		}
+		assert.Equal(t, len(tm.Indices), count)
+	}
+}
@@ -1,5 +1,12 @@
 package ofbx
 
+import (
+	"math"
+
+	"github.com/oakmound/oak/v2/alg"
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+)
+
 // TextureType determines how a texture be used
 type TextureType int
 
@@ -7,20 +14,134 @@ type TextureType int
 const (
 	DIFFUSE      TextureType = iota
 	NORMAL       TextureType = iota
+	SPECULAR     TextureType = iota
+	EMISSIVE     TextureType = iota
+	BUMP         TextureType = iota
+	TRANSPARENT  TextureType = iota
+	REFLECTION   TextureType = iota
+	SHININESS    TextureType = iota
+	DISPLACEMENT TextureType = iota
+	AMBIENT      TextureType = iota
 	TextureCOUNT TextureType = iota
 )
 
+// textureProperties lists the material properties a texture type can be connected to, by preference
+var textureProperties = [TextureCOUNT][]string{
+	DIFFUSE:      {"DiffuseColor", "Diffuse", "DiffuseFactor"},
+	NORMAL:       {"NormalMap"},
+	SPECULAR:     {"SpecularColor", "SpecularFactor"},
+	EMISSIVE:     {"EmissiveColor", "EmissiveFactor"},
+	BUMP:         {"Bump"},
+	TRANSPARENT:  {"TransparentColor", "TransparencyFactor"},
+	REFLECTION:   {"ReflectionColor", "ReflectionFactor"},
+	SHININESS:    {"ShininessExponent", "Shininess"},
+	DISPLACEMENT: {"DisplacementColor", "VectorDisplacementColor"},
+	AMBIENT:      {"AmbientColor", "AmbientFactor"},
+}
+
+// Property returns the material property the texture type is usually connected to
+func (t TextureType) Property() string {
+	if t < 0 || t >= TextureCOUNT {
+		return ""
+	}
+	return textureProperties[t][0]
+}
+
+// WrapMode tells how a texture is sampled outside of the [0,1] UV range
+type WrapMode int
+
+// WrapMode options
+const (
+	WrapRepeat WrapMode = iota
+	WrapClamp  WrapMode = iota
+)
+
+// AlphaSource tells where a texture's alpha comes from
+type AlphaSource int
+
+// AlphaSource options
+const (
+	AlphaSourceNone         AlphaSource = iota
+	AlphaSourceRGBIntensity AlphaSource = iota
+	AlphaSourceBlack        AlphaSource = iota
+)
+
+// UVMatrix is a 3x3 column major matrix transforming homogeneous UV coordinates
+type UVMatrix [9]float64
+
+// Mul returns the product m*m2
+func (m UVMatrix) Mul(m2 UVMatrix) UVMatrix {
+	var out UVMatrix
+	for col := 0; col < 3; col++ {
+		for row := 0; row < 3; row++ {
+			for k := 0; k < 3; k++ {
+				out[col*3+row] += m[k*3+row] * m2[col*3+k]
+			}
+		}
+	}
+	return out
+}
+
+// Apply transforms a UV coordinate
+func (m UVMatrix) Apply(uv floatgeom.Point2) floatgeom.Point2 {
+	return floatgeom.Point2{
+		m[0]*uv[0] + m[3]*uv[1] + m[6],
+		m[1]*uv[0] + m[4]*uv[1] + m[7],
+	}
+}
+
+func uvTranslation(x, y float64) UVMatrix {
+	return UVMatrix{1, 0, 0, 0, 1, 0, x, y, 1}
+}
+
 // Texture is a texture file on an object
 type Texture struct {
 	Object
 	filename         *DataView
 	relativeFilename *DataView
+	video            *Video
+
+	// UVSet names the UV set of the geometry to sample with, empty for the default one
+	UVSet                string
+	WrapModeU, WrapModeV WrapMode
+	// Translation, Rotation and Scaling transform the UVs; only X and Y of Translation and Scaling and Z
+	// of Rotation, in degrees, apply. Rotation and scaling happen around their pivots.
+	Translation, Rotation, Scaling floatgeom.Point3
+	RotationPivot, ScalingPivot    floatgeom.Point3
+	UVSwap                         bool
+	UseMipMap                      bool
+	AlphaSource                    AlphaSource
+	PremultiplyAlpha               bool
+	// Cropping holds the left, top, right and bottom pixels to crop from the image
+	Cropping [4]int
+	// TextureAlphaSource is the legacy Texture_Alpha_Source, such as "None" or "Alpha_Black"
+	TextureAlphaSource string
+}
+
+// UVTransform returns the matrix mapping the geometry's UVs to texture coordinates:
+// translation * rotation around its pivot * scaling around its pivot, with U and V swapped first if UVSwap is set
+func (t *Texture) UVTransform() UVMatrix {
+	s, c := math.Sincos(t.Rotation[2] * alg.DegToRad)
+	rotation := UVMatrix{c, s, 0, -s, c, 0, 0, 0, 1}
+	scaling := UVMatrix{t.Scaling[0], 0, 0, 0, t.Scaling[1], 0, 0, 0, 1}
+	m := uvTranslation(t.Translation[0], t.Translation[1]).
+		Mul(uvTranslation(t.RotationPivot[0], t.RotationPivot[1])).
+		Mul(rotation).
+		Mul(uvTranslation(-t.RotationPivot[0], -t.RotationPivot[1])).
+		Mul(uvTranslation(t.ScalingPivot[0], t.ScalingPivot[1])).
+		Mul(scaling).
+		Mul(uvTranslation(-t.ScalingPivot[0], -t.ScalingPivot[1]))
+	if t.UVSwap {
+		m = m.Mul(UVMatrix{0, 1, 0, 1, 0, 0, 0, 0, 1})
+	}
+	return m
 }
 
 // NewTexture creates a texture
 func NewTexture(scene *Scene, element *Element) *Texture {
 	t := &Texture{
-		Object: *NewObject(scene, element),
+		Object:  *NewObject(scene, element),
+		Scaling: floatgeom.Point3{1, 1, 1},
 	}
 	return t
 }
@@ -38,6 +159,24 @@ func (t *Texture) GetRelativeFileName() *DataView {
 	return t.relativeFilename
 }
 
+// Video returns the video object the texture reads its image from, or nil
+func (t *Texture) Video() *Video {
+	return t.video
+}
+
+// Media returns the file name of the texture's image and its bytes when they are embedded in the file.
+// content is nil when the image has to be loaded from disk.
+func (t *Texture) Media() (filename string, content []byte) {
+	if t.video != nil {
+		filename = t.video.Filename
+		content = t.video.Content
+	}
+	if filename == "" && t.filename != nil {
+		filename = t.filename.String()
+	}
+	return filename, content
+}
+
 func (t *Texture) String() string {
 	s := "Texture: " + t.Object.String()
 	s += ", filename: " + t.filename.String()
@@ -0,0 +1,141 @@
+package ofbx
+
+import (
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TextureResolver finds the files of textures in a file system. FBX files store the paths of the
+// machine they were exported on, so the resolver tries, in every search path: the relative file name,
+// then shorter and shorter tails of the paths down to the base name, each matched exactly and then
+// case-insensitively.
+type TextureResolver struct {
+	FS fs.FS
+	// SearchPaths are the directories of FS to look in, in order. No search paths means the root.
+	SearchPaths []string
+}
+
+// NewTextureResolver creates a resolver over fsys, such as os.DirFS of the FBX file's directory
+func NewTextureResolver(fsys fs.FS, searchPaths ...string) *TextureResolver {
+	return &TextureResolver{FS: fsys, SearchPaths: searchPaths}
+}
+
+// Resolve returns the path within FS of the texture's file
+func (r *TextureResolver) Resolve(t *Texture) (string, error) {
+	names := make([]string, 0, 4)
+	if t.relativeFilename != nil {
+		names = append(names, t.relativeFilename.String())
+	}
+	if t.filename != nil {
+		names = append(names, t.filename.String())
+	}
+	if t.video != nil {
+		names = append(names, t.video.RelativeFilename, t.video.Filename)
+	}
+	if p, ok := r.resolve(names...); ok {
+		return p, nil
+	}
+	return "", errors.Errorf("Texture %q not found", t.Name())
+}
+
+// ResolveFile returns the path within FS of the first of names, as stored in an FBX file, that can be found
+func (r *TextureResolver) ResolveFile(names ...string) (string, error) {
+	if p, ok := r.resolve(names...); ok {
+		return p, nil
+	}
+	return "", errors.Errorf("File %q not found", strings.Join(names, ", "))
+}
+
+// ResolveScene resolves every texture of the scene, returning the paths found and the textures that were not
+func (r *TextureResolver) ResolveScene(s *Scene) (map[*Texture]string, []*Texture) {
+	resolved := make(map[*Texture]string)
+	unresolved := make([]*Texture, 0)
+	for _, obj := range s.ObjectMap {
+		t, ok := obj.(*Texture)
+		if !ok {
+			continue
+		}
+		if p, err := r.Resolve(t); err == nil {
+			resolved[t] = p
+		} else {
+			unresolved = append(unresolved, t)
+		}
+	}
+	return resolved, unresolved
+}
+
+func (r *TextureResolver) resolve(names ...string) (string, bool) {
+	dirs := r.SearchPaths
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+	for _, dir := range dirs {
+		for _, name := range names {
+			for _, candidate := range pathCandidates(name) {
+				p := path.Join(dir, candidate)
+				if !fs.ValidPath(p) {
+					continue
+				}
+				if found, ok := findFile(r.FS, p); ok {
+					return found, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+// pathCandidates returns the slash separated paths to try for a file name: the whole name if it is
+// relative, then its tails from the longest to the base name
+func pathCandidates(name string) []string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" {
+		return nil
+	}
+	candidates := make([]string, 0)
+	absolute := strings.HasPrefix(name, "/") || (len(name) > 1 && name[1] == ':')
+	if !absolute {
+		candidates = append(candidates, name)
+	}
+	parts := strings.Split(name, "/")
+	for i := 1; i < len(parts); i++ {
+		tail := parts[i:]
+		if tail[0] == ".." || tail[0] == "." || tail[0] == "" {
+			continue
+		}
+		candidates = append(candidates, path.Join(tail...))
+	}
+	return candidates
+}
+
+// findFile looks for p in fsys, matching each element case-insensitively when there is no exact match
+func findFile(fsys fs.FS, p string) (string, bool) {
+	if info, err := fs.Stat(fsys, p); err == nil && !info.IsDir() {
+		return p, true
+	}
+	dir := "."
+	for _, elem := range strings.Split(p, "/") {
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil {
+			return "", false
+		}
+		found := false
+		for _, entry := range entries {
+			if strings.EqualFold(entry.Name(), elem) {
+				dir = path.Join(dir, entry.Name())
+				found = true
+				break
+			}
+		}
+		if !found {
+			return "", false
+		}
+	}
+	if info, err := fs.Stat(fsys, dir); err != nil || info.IsDir() {
+		return "", false
+	}
+	return dir, true
+}
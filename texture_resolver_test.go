@@ -0,0 +1,67 @@
+package ofbx
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathCandidates(t *testing.T) {
+	assert.Equal(t, []string{"../../sourceimages/a.jpg", "sourceimages/a.jpg", "a.jpg"}, pathCandidates(`..\..\sourceimages\a.jpg`))
+	assert.Equal(t, []string{"Users/me/a.jpg", "me/a.jpg", "a.jpg"}, pathCandidates(`C:\Users\me\a.jpg`))
+	assert.Equal(t, []string{"a.jpg"}, pathCandidates("a.jpg"))
+	assert.Empty(t, pathCandidates(""))
+}
+
+func TestTextureResolverFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"models/city.fbx":           {},
+		"models/Textures/Wood.PNG":  {Data: []byte("wood")},
+		"shared/sourceimages/a.jpg": {Data: []byte("a")},
+		"shared/b.jpg":              {Data: []byte("b")},
+	}
+	r := NewTextureResolver(fsys, "models", "shared")
+
+	p, err := r.ResolveFile(`textures\wood.png`)
+	require.NoError(t, err)
+	assert.Equal(t, "models/Textures/Wood.PNG", p)
+
+	// The relative path leaves the search path but stays in the file system
+	p, err = r.ResolveFile(`..\shared\sourceimages\a.jpg`)
+	require.NoError(t, err)
+	assert.Equal(t, "shared/sourceimages/a.jpg", p)
+
+	p, err = r.ResolveFile(`D:\project\sourceimages\A.jpg`)
+	require.NoError(t, err)
+	assert.Equal(t, "shared/sourceimages/a.jpg", p)
+
+	p, err = r.ResolveFile(`/home/artist/missing.jpg`, "b.jpg")
+	require.NoError(t, err)
+	assert.Equal(t, "shared/b.jpg", p)
+
+	_, err = r.ResolveFile(`..\..\..\c.jpg`, "missing.jpg")
+	assert.Error(t, err)
+	// Directories are not textures
+	_, err = r.ResolveFile("textures")
+	assert.Error(t, err)
+}
+
+func TestTextureResolverScene(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	// The file references ..\..\sourceimages\Ro_*.jpg and D:\...\sourceimages\Ro_*.jpg
+	resolved, unresolved := NewTextureResolver(os.DirFS("testdata")).ResolveScene(scene)
+	assert.Empty(t, unresolved)
+	require.NotEmpty(t, resolved)
+	for tex, p := range resolved {
+		assert.Regexp(t, `^Ro_.*\.jpg$`, p, tex.Name())
+	}
+
+	resolved, unresolved = NewTextureResolver(fstest.MapFS{}).ResolveScene(scene)
+	assert.Empty(t, resolved)
+	require.NotEmpty(t, unresolved)
+	_, err := NewTextureResolver(fstest.MapFS{}).Resolve(unresolved[0])
+	assert.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package ofbx
+
+import (
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertUVInDelta(t *testing.T, expected, actual floatgeom.Point2) {
+	assert.InDelta(t, expected[0], actual[0], 1e-9, "%v != %v", expected, actual)
+	assert.InDelta(t, expected[1], actual[1], 1e-9, "%v != %v", expected, actual)
+}
+
+func TestTextureUVTransform(t *testing.T) {
+	tex := NewTexture(&Scene{}, &Element{ID: NewDataView("Texture")})
+	assert.Equal(t, UVMatrix{1, 0, 0, 0, 1, 0, 0, 0, 1}, tex.UVTransform())
+
+	// Tiled twice and offset
+	tex.Scaling = floatgeom.Point3{2, 2, 1}
+	tex.Translation = floatgeom.Point3{0.5, 0.25, 0}
+	assertUVInDelta(t, floatgeom.Point2{1.5, 1.25}, tex.UVTransform().Apply(floatgeom.Point2{0.5, 0.5}))
+
+	// A quarter turn around the center of the texture
+	tex.Scaling = floatgeom.Point3{1, 1, 1}
+	tex.Translation = floatgeom.Point3{}
+	tex.Rotation = floatgeom.Point3{0, 0, 90}
+	tex.RotationPivot = floatgeom.Point3{0.5, 0.5, 0}
+	assertUVInDelta(t, floatgeom.Point2{1, 0}, tex.UVTransform().Apply(floatgeom.Point2{0, 0}))
+	assertUVInDelta(t, floatgeom.Point2{0.5, 0.5}, tex.UVTransform().Apply(floatgeom.Point2{0.5, 0.5}))
+
+	tex.Rotation = floatgeom.Point3{}
+	tex.UVSwap = true
+	assertUVInDelta(t, floatgeom.Point2{0.75, 0.25}, tex.UVTransform().Apply(floatgeom.Point2{0.25, 0.75}))
+}
+
+func TestParseTextureSampling(t *testing.T) {
+	element := newTextureElement(1,
+		newTestElement("Properties70", nil,
+			newP70("UVSet", "KString", newStringProperty("lightmap")),
+			newP70("WrapModeU", "enum", newIntegerProperty(1)),
+			newP70("Translation", "Vector", newDoubleProperty(0.5), newDoubleProperty(0.25), newDoubleProperty(0)),
+			newP70("Rotation", "Vector", newDoubleProperty(0), newDoubleProperty(0), newDoubleProperty(45)),
+			newP70("UseMipMap", "bool", newIntegerProperty(1)),
+			newP70("AlphaSource", "enum", newIntegerProperty(2)),
+			newP70("PremultiplyAlpha", "bool", &Property{Type: BOOL, value: NewDataView("\x01")}),
+		),
+		newTestElement("ModelUVScaling", []*Property{newDoubleProperty(3), newDoubleProperty(4)}),
+		newTestElement("Texture_Alpha_Source", []*Property{newStringProperty("Alpha_Black")}),
+		newTestElement("Cropping", []*Property{newIntegerProperty(1), newIntegerProperty(2), newIntegerProperty(3), newIntegerProperty(4)}),
+	)
+	tex := parseTexture(&Scene{}, element)
+	assert.Equal(t, "lightmap", tex.UVSet)
+	assert.Equal(t, WrapClamp, tex.WrapModeU)
+	assert.Equal(t, WrapRepeat, tex.WrapModeV)
+	assert.Equal(t, floatgeom.Point3{0.5, 0.25, 0}, tex.Translation)
+	assert.Equal(t, floatgeom.Point3{0, 0, 45}, tex.Rotation)
+	assert.Equal(t, floatgeom.Point3{3, 4, 1}, tex.Scaling)
+	assert.True(t, tex.UseMipMap)
+	assert.True(t, tex.PremultiplyAlpha)
+	assert.Equal(t, AlphaSourceBlack, tex.AlphaSource)
+	assert.Equal(t, "Alpha_Black", tex.TextureAlphaSource)
+	assert.Equal(t, [4]int{1, 2, 3, 4}, tex.Cropping)
+}
+
+func TestTextureSamplingFromScene(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	tex := scene.Meshes[0].Materials[0].GetTexture(DIFFUSE)
+	require.NotNil(t, tex)
+	assert.Equal(t, "map1", tex.UVSet)
+	assert.Equal(t, "None", tex.TextureAlphaSource)
+	assert.NotNil(t, scene.Meshes[0].Geometry.UVSetByName(tex.UVSet))
+	assert.Equal(t, UVMatrix{1, 0, 0, 0, 1, 0, 0, 0, 1}, tex.UVTransform())
+}
@@ -0,0 +1,215 @@
+package ofbx
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+
+	"github.com/pkg/errors"
+)
+
+// TGA image types
+const (
+	tgaColorMapped    = 1
+	tgaTrueColor      = 2
+	tgaGrayscale      = 3
+	tgaRLEColorMapped = 9
+	tgaRLETrueColor   = 10
+	tgaRLEGrayscale   = 11
+)
+
+const tgaHeaderSize = 18
+
+// tgaFooterSignature ends TGA 2.0 files
+const tgaFooterSignature = "TRUEVISION-XFILE.\x00"
+
+// decodeTGA decodes a color mapped, true color or grayscale TGA, run length encoded or not
+func decodeTGA(data []byte) (image.Image, error) {
+	if len(data) < tgaHeaderSize {
+		return nil, errors.New("Invalid TGA: header too short")
+	}
+	idLength := int(data[0])
+	colorMapType := data[1]
+	imageType := data[2]
+	mapFirst := int(binary.LittleEndian.Uint16(data[3:]))
+	mapLength := int(binary.LittleEndian.Uint16(data[5:]))
+	mapDepth := int(data[7])
+	width := int(binary.LittleEndian.Uint16(data[12:]))
+	height := int(binary.LittleEndian.Uint16(data[14:]))
+	depth := int(data[16])
+	descriptor := data[17]
+
+	rle := imageType >= tgaRLEColorMapped
+	baseType := imageType
+	if rle {
+		baseType -= tgaRLEColorMapped - tgaColorMapped
+	}
+	switch baseType {
+	case tgaColorMapped, tgaTrueColor, tgaGrayscale:
+	default:
+		return nil, errors.Errorf("Invalid TGA: unsupported image type %d", imageType)
+	}
+	if width == 0 || height == 0 {
+		return nil, errors.New("Invalid TGA: empty image")
+	}
+
+	offset := tgaHeaderSize + idLength
+	var palette []color.NRGBA
+	if colorMapType == 1 {
+		entrySize := (mapDepth + 7) / 8
+		end := offset + mapLength*entrySize
+		if entrySize == 0 || end > len(data) {
+			return nil, errors.New("Invalid TGA: truncated color map")
+		}
+		palette = make([]color.NRGBA, mapLength)
+		for i := range palette {
+			c, err := tgaColor(data[offset+i*entrySize:], mapDepth, false)
+			if err != nil {
+				return nil, err
+			}
+			palette[i] = c
+		}
+		offset = end
+	}
+	if baseType == tgaColorMapped && palette == nil {
+		return nil, errors.New("Invalid TGA: color mapped image without color map")
+	}
+
+	pixelSize := (depth + 7) / 8
+	if pixelSize == 0 || pixelSize > 4 {
+		return nil, errors.Errorf("Invalid TGA: unsupported pixel depth %d", depth)
+	}
+	pixels, err := tgaPixels(data[offset:], width*height, pixelSize, rle)
+	if err != nil {
+		return nil, err
+	}
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	topDown := descriptor&0x20 != 0
+	rightToLeft := descriptor&0x10 != 0
+	for i := 0; i < width*height; i++ {
+		px := pixels[i*pixelSize : (i+1)*pixelSize]
+		var c color.NRGBA
+		switch baseType {
+		case tgaColorMapped:
+			idx := int(px[0])
+			if pixelSize > 1 {
+				idx = int(binary.LittleEndian.Uint16(px))
+			}
+			idx -= mapFirst
+			if idx < 0 || idx >= len(palette) {
+				return nil, errors.New("Invalid TGA: color map index out of range")
+			}
+			c = palette[idx]
+		case tgaGrayscale:
+			c = color.NRGBA{px[0], px[0], px[0], 255}
+			if pixelSize > 1 {
+				c.A = px[1]
+			}
+		default:
+			c, err = tgaColor(px, depth, descriptor&0x0f != 0)
+			if err != nil {
+				return nil, err
+			}
+		}
+		x, y := i%width, i/width
+		if rightToLeft {
+			x = width - 1 - x
+		}
+		if !topDown {
+			y = height - 1 - y
+		}
+		img.SetNRGBA(x, y, c)
+	}
+	return img, nil
+}
+
+// isTGAHeader reports whether data starts with a header decodeTGA could read
+func isTGAHeader(data []byte) bool {
+	if len(data) < tgaHeaderSize || data[1] > 1 {
+		return false
+	}
+	switch data[2] {
+	case tgaColorMapped, tgaTrueColor, tgaGrayscale, tgaRLEColorMapped, tgaRLETrueColor, tgaRLEGrayscale:
+	default:
+		return false
+	}
+	switch data[16] {
+	case 8, 15, 16, 24, 32:
+	default:
+		return false
+	}
+	return binary.LittleEndian.Uint16(data[12:]) != 0 && binary.LittleEndian.Uint16(data[14:]) != 0
+}
+
+// tgaPixels returns count pixels of size bytes, expanding run length packets
+func tgaPixels(data []byte, count, size int, rle bool) ([]byte, error) {
+	if !rle {
+		if len(data) < count*size {
+			return nil, errors.New("Invalid TGA: truncated pixel data")
+		}
+		return data[:count*size], nil
+	}
+	// A packet of a header and one pixel repeats it at most 128 times, so the data bounds how many
+	// pixels it can hold
+	if count > len(data)/(1+size)*128 {
+		return nil, errors.New("Invalid TGA: truncated run length data")
+	}
+	out := make([]byte, 0, len(data))
+	for len(out) < count*size {
+		if len(data) == 0 {
+			return nil, errors.New("Invalid TGA: truncated run length data")
+		}
+		header := data[0]
+		data = data[1:]
+		n := int(header&0x7f) + 1
+		if header&0x80 != 0 {
+			if len(data) < size {
+				return nil, errors.New("Invalid TGA: truncated run length data")
+			}
+			for i := 0; i < n; i++ {
+				out = append(out, data[:size]...)
+			}
+			data = data[size:]
+		} else {
+			if len(data) < n*size {
+				return nil, errors.New("Invalid TGA: truncated run length data")
+			}
+			out = append(out, data[:n*size]...)
+			data = data[n*size:]
+		}
+	}
+	return out[:count*size], nil
+}
+
+// tgaColor reads a little endian BGR(A) pixel of the given bit depth
+func tgaColor(px []byte, depth int, hasAlpha bool) (color.NRGBA, error) {
+	switch depth {
+	case 15, 16:
+		if len(px) < 2 {
+			return color.NRGBA{}, errors.New("Invalid TGA: truncated color")
+		}
+		v := binary.LittleEndian.Uint16(px)
+		c := color.NRGBA{
+			R: uint8((v >> 10 & 0x1f) * 255 / 31),
+			G: uint8((v >> 5 & 0x1f) * 255 / 31),
+			B: uint8((v & 0x1f) * 255 / 31),
+			A: 255,
+		}
+		if depth == 16 && hasAlpha && v&0x8000 == 0 {
+			c.A = 0
+		}
+		return c, nil
+	case 24:
+		if len(px) < 3 {
+			return color.NRGBA{}, errors.New("Invalid TGA: truncated color")
+		}
+		return color.NRGBA{px[2], px[1], px[0], 255}, nil
+	case 32:
+		if len(px) < 4 {
+			return color.NRGBA{}, errors.New("Invalid TGA: truncated color")
+		}
+		return color.NRGBA{px[2], px[1], px[0], px[3]}, nil
+	}
+	return color.NRGBA{}, errors.Errorf("Invalid TGA: unsupported color depth %d", depth)
+}
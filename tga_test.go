@@ -0,0 +1,94 @@
+package ofbx
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tgaHeader(imageType byte, width, height int, depth, descriptor byte) []byte {
+	h := make([]byte, tgaHeaderSize)
+	h[2] = imageType
+	h[12], h[13] = byte(width), byte(width>>8)
+	h[14], h[15] = byte(height), byte(height>>8)
+	h[16] = depth
+	h[17] = descriptor
+	return h
+}
+
+func TestDecodeTGATrueColor(t *testing.T) {
+	// Bottom-left origin: the first row in the file is the bottom one
+	data := append(tgaHeader(tgaTrueColor, 2, 2, 24, 0),
+		0, 0, 255, 0, 255, 0,
+		255, 0, 0, 255, 255, 255,
+	)
+	img, err := decodeTGA(data)
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{0, 0, 255, 255}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{255, 255, 255, 255}, img.At(1, 0))
+	assert.Equal(t, color.NRGBA{255, 0, 0, 255}, img.At(0, 1))
+	assert.Equal(t, color.NRGBA{0, 255, 0, 255}, img.At(1, 1))
+
+	_, err = decodeTGA(data[:len(data)-1])
+	assert.Error(t, err)
+}
+
+func TestDecodeTGARunLength(t *testing.T) {
+	// Top-left origin, a run of 3 then a raw packet of 1
+	data := append(tgaHeader(tgaRLETrueColor, 2, 2, 32, 0x28),
+		0x82, 1, 2, 3, 128,
+		0x00, 4, 5, 6, 7,
+	)
+	img, err := decodeTGA(data)
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{3, 2, 1, 128}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{3, 2, 1, 128}, img.At(0, 1))
+	assert.Equal(t, color.NRGBA{6, 5, 4, 7}, img.At(1, 1))
+
+	_, err = decodeTGA(data[:len(data)-2])
+	assert.Error(t, err)
+
+	// Dimensions that a few bytes of runs can't hold fail before anything is allocated for them
+	_, err = decodeTGA(append(tgaHeader(tgaRLETrueColor, 65535, 65535, 32, 0), 0xff, 1, 2, 3, 4))
+	assert.Error(t, err)
+}
+
+func TestDecodeTGAColorMappedAndGray(t *testing.T) {
+	data := tgaHeader(tgaColorMapped, 2, 1, 8, 0x20)
+	data[1] = 1
+	data[5], data[7] = 2, 24
+	data = append(data, 0, 0, 255, 255, 0, 0, 1, 0)
+	img, err := decodeTGA(data)
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{0, 0, 255, 255}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{255, 0, 0, 255}, img.At(1, 0))
+
+	img, err = decodeTGA(append(tgaHeader(tgaGrayscale, 1, 1, 8, 0), 77))
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{77, 77, 77, 255}, img.At(0, 0))
+
+	_, err = decodeTGA(tgaHeader(tgaColorMapped, 1, 1, 8, 0))
+	assert.Error(t, err)
+	_, err = decodeTGA(tgaHeader(32, 1, 1, 8, 0))
+	assert.Error(t, err)
+}
+
+func TestDecodeImageSniffsTGA(t *testing.T) {
+	data := append(tgaHeader(tgaTrueColor, 1, 1, 24, 0), 255, 0, 0)
+
+	// A TGA 1.0 file is recognised by its header, whatever it is called
+	img, err := decodeImage("texture.dat", data)
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{0, 0, 255, 255}, img.At(0, 0))
+
+	// A TGA 2.0 file by its footer
+	footer := append(make([]byte, 8), tgaFooterSignature...)
+	img, err = decodeImage("texture", append(data, footer...))
+	require.NoError(t, err)
+	assert.Equal(t, color.NRGBA{0, 0, 255, 255}, img.At(0, 0))
+
+	_, err = decodeImage("texture.dat", []byte("not an image at all"))
+	assert.Error(t, err)
+}
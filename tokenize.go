@@ -2,6 +2,8 @@ package ofbx
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/binary"
 	"io"
 	"strconv"
@@ -29,6 +31,18 @@ func (h Header) String() string {
 type Cursor struct {
 	*bufio.Reader
 	cr *CountReader
+
+	ctx      context.Context
+	progress ProgressFunc
+	total    int64
+	// reported is how much had been read when progress was last told
+	reported int64
+
+	limits Limits
+	// depth is how deep the element being read is nested
+	depth int
+	// decompressed is how many bytes the compressed arrays read so far inflate to
+	decompressed int64
 }
 
 // ReadSoFar returns how much of the data has been read
@@ -55,8 +69,10 @@ func (c *Cursor) readLongString() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	byt := make([]byte, length)
-	_, err = io.ReadFull(c, byt)
+	if err := c.limits.checkStringLength(int(length)); err != nil {
+		return "", err
+	}
+	byt, err := c.readBytes(int(length))
 	if err != nil {
 		return "", err
 	}
@@ -75,70 +91,106 @@ func (c *Cursor) readElementOffset(version uint16) (uint64, error) {
 	return uint64(i), err
 }
 
-func (c *Cursor) readBytes(length int) []byte {
-	tempArr := make([]byte, length)
-	_, err := io.ReadFull(c, tempArr)
-	if err != nil {
-		//fmt.Println(err)
+// readBytesChunk is the most readBytes allocates before the data has been read, as lengths come
+// from the file and may be far larger than it
+const readBytesChunk = 1 << 16
+
+// maxPreallocatedProperties is the most properties readElement allocates for before reading them
+const maxPreallocatedProperties = 1 << 10
+
+func (c *Cursor) readBytes(length int) ([]byte, error) {
+	if length <= readBytesChunk {
+		tempArr := make([]byte, length)
+		if _, err := io.ReadFull(c, tempArr); err != nil {
+			return nil, errors.Wrapf(err, "Failed to read %d bytes", length)
+		}
+		return tempArr, nil
+	}
+	var buf bytes.Buffer
+	if _, err := io.CopyN(&buf, c, int64(length)); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, errors.Wrapf(err, "Failed to read %d bytes", length)
 	}
-	return tempArr
+	return buf.Bytes(), nil
 }
 
 func (c *Cursor) readProperty() (*Property, error) {
 	if _, err := c.Peek(1); err != nil {
-		return nil, errors.New("Reading Past End")
+		return nil, errors.Wrap(err, "Reading Past End")
 	}
 	prop := Property{}
-	typ, _, err := c.ReadRune()
+	typ, err := c.ReadByte()
 	if err != nil {
-		//fmt.Println(err)
+		return nil, err
 	}
 	prop.Type = PropertyType(typ)
 	var val string
+	var byts []byte
 	//fmt.Println("Got property type:", string(prop.typ))
 	switch prop.Type {
 	case 'S':
 		val, err = c.readLongString()
 	case 'Y':
-		val = string(c.readBytes(2))
+		byts, err = c.readBytes(2)
 	case 'C':
-		val = string(c.readBytes(1))
+		byts, err = c.readBytes(1)
 	case 'I':
-		val = string(c.readBytes(4))
+		byts, err = c.readBytes(4)
 	case 'F':
-		val = string(c.readBytes(4))
+		byts, err = c.readBytes(4)
 	case 'D':
-		val = string(c.readBytes(8))
+		byts, err = c.readBytes(8)
 	case 'L':
-		val = string(c.readBytes(8))
+		byts, err = c.readBytes(8)
 	case 'R':
-		tmp := c.readBytes(4)
+		var tmp []byte
+		if tmp, err = c.readBytes(4); err != nil {
+			return nil, err
+		}
 		length := int(binary.LittleEndian.Uint32(tmp))
-		val = string(append(tmp, c.readBytes(length)...))
+		byts, err = c.readBytes(length)
+		byts = append(tmp, byts...)
 	case 'b', 'f', 'd', 'l', 'i':
-		unCompressedLength := c.readBytes(4)
-		encoding := c.readBytes(4)
-		compressedLength := c.readBytes(4)
+		var lengths []byte
+		if lengths, err = c.readBytes(12); err != nil {
+			return nil, err
+		}
+		unCompressedLength := lengths[0:4]
+		encoding := lengths[4:8]
+		compressedLength := lengths[8:12]
 		length := int(binary.LittleEndian.Uint32(compressedLength))
+		elemCount := int(binary.LittleEndian.Uint32(unCompressedLength))
+		if err := c.limits.checkArrayLength(elemCount); err != nil {
+			return nil, err
+		}
 		if int(binary.LittleEndian.Uint32(encoding)) == 0 {
-			elemCount := int(binary.LittleEndian.Uint32(unCompressedLength))
 			switch prop.Type {
 			case 'f', 'i':
 				length = elemCount * 4
 			case 'd', 'l':
 				length = elemCount * 8
 			}
+		} else {
+			c.decompressed += int64(elemCount * prop.Type.Size())
+			if err := c.limits.checkDecompressedBytes(c.decompressed); err != nil {
+				return nil, err
+			}
 		}
 		prop.Encoding = binary.LittleEndian.Uint32(encoding)
 		prop.compressedLength = binary.LittleEndian.Uint32(compressedLength)
-		prop.Count = int(binary.LittleEndian.Uint32(unCompressedLength))
+		prop.Count = elemCount
 		//fmt.Println("prop lengths", unCompressedLength, compressedLength, "props encoding", encoding)
-		val = string(c.readBytes(length))
+		byts, err = c.readBytes(length)
 	default:
 		return nil, errors.New("Did not know this property:" + string(prop.Type))
 	}
 	if err != nil {
-		//fmt.Println(err)
+		return nil, err
+	}
+	if byts != nil {
+		val = string(byts)
 	}
 
 	prop.value = NewDataView(val)
@@ -146,7 +198,61 @@ func (c *Cursor) readProperty() (*Property, error) {
 	return &prop, nil
 }
 
+// checkContext reports progress and returns the context's error once it is done
+func (c *Cursor) checkContext() error {
+	if c.ctx != nil {
+		if err := c.ctx.Err(); err != nil {
+			return err
+		}
+	}
+	if c.progress != nil {
+		if read := int64(c.ReadSoFar()); read-c.reported >= c.progressStep() {
+			c.reported = read
+			c.progress(read, c.total)
+		}
+	}
+	return nil
+}
+
+// progressInterval is how many bytes are read between progress reports when the size isn't known
+const progressInterval = 1 << 16
+
+// progressStep is how far reading goes between progress reports: a percent of the file, or
+// progressInterval when its size isn't known
+func (c *Cursor) progressStep() int64 {
+	if c.total > 0 {
+		return c.total/100 + 1
+	}
+	return progressInterval
+}
+
+// checkObjects fails once the top level Objects element being read has as many children as
+// the limit allows, before another is read
+func (c *Cursor) checkObjects(element *Element) error {
+	if c.depth != 1 || element.ID == nil || element.ID.String() != "Objects" {
+		return nil
+	}
+	if err := c.limits.checkObjects(len(element.Children) + 1); err != nil {
+		return c.readError(element, err)
+	}
+	return nil
+}
+
+// readError wraps err in a ParseError at the cursor's position
+func (c *Cursor) readError(element *Element, err error) error {
+	return elementError(element, &ParseError{Offset: int64(c.ReadSoFar()), Err: err})
+}
+
 func (c *Cursor) readElement(version uint16) (*Element, error) {
+	if err := c.checkContext(); err != nil {
+		return nil, err
+	}
+	element := Element{offset: int64(c.ReadSoFar())}
+	c.depth++
+	defer func() { c.depth-- }()
+	if err := c.limits.checkDepth(c.depth); err != nil {
+		return nil, c.readError(nil, err)
+	}
 	v, _ := c.Peek(12)
 	footer := true
 	for _, b := range v {
@@ -164,34 +270,39 @@ func (c *Cursor) readElement(version uint16) (*Element, error) {
 
 	endOffset, err := c.readElementOffset(version)
 	if err != nil {
-		return nil, err
+		return nil, c.readError(nil, errors.Wrap(err, "Failed to read element end offset"))
 	}
 	//fmt.Println("Obtained element end offset", endOffset)
 	propCt, err := c.readElementOffset(version)
 	if err != nil {
-		return nil, err
+		return nil, c.readError(nil, errors.Wrap(err, "Failed to read property count"))
 	}
 	//fmt.Println("Obtained element prop count", propCt)
 	_, err = c.readElementOffset(version)
 	if err != nil {
-		return nil, err
+		return nil, c.readError(nil, errors.Wrap(err, "Failed to read property list length"))
 	}
 	//fmt.Println("Obtained property list length", prop_list_length)
 	id, err := c.readShortString()
 	if err != nil {
-		return nil, err
+		return nil, c.readError(nil, errors.Wrap(err, "Failed to read element name"))
 	}
 	//fmt.Println("Read short string", id)
 
-	element := Element{}
 	element.ID = NewDataView(id)
 
-	element.Properties = make([]*Property, propCt)
+	// The count comes from the file, so it isn't trusted with the allocation
+	capacity := propCt
+	if capacity > maxPreallocatedProperties {
+		capacity = maxPreallocatedProperties
+	}
+	element.Properties = make([]*Property, 0, capacity)
 	for i := uint64(0); i < propCt; i++ {
-		element.Properties[i], err = c.readProperty()
+		prop, err := c.readProperty()
 		if err != nil {
-			return nil, err
+			return nil, c.readError(&element, errors.Wrapf(err, "Failed to read property %d", i))
 		}
+		element.Properties = append(element.Properties, prop)
 	}
 
 	if uint64(c.ReadSoFar()) >= endOffset {
@@ -205,9 +316,15 @@ func (c *Cursor) readElement(version uint16) (*Element, error) {
 
 	//fmt.Print("sizes pre children ", c.ReadSoFar(), endOffset, uint64(blockSentinelLength))
 	for uint64(c.ReadSoFar()) < endOffset-uint64(blockSentinelLength) {
+		if err := c.checkObjects(&element); err != nil {
+			return nil, err
+		}
 		child, err := c.readElement(version)
 		if err != nil {
-			return nil, errors.Wrap(err, "ReadingChild element failed")
+			return nil, elementError(&element, err)
+		}
+		if child == nil {
+			return nil, c.readError(&element, errors.New("Unexpected end of children"))
 		}
 		element.Children = append(element.Children, child)
 		if uint64(c.ReadSoFar()) > endOffset {
@@ -217,26 +334,35 @@ func (c *Cursor) readElement(version uint16) (*Element, error) {
 	if uint64(c.ReadSoFar()) > endOffset {
 		//fmt.Println("Read past where we were supposed to!!", c.ReadSoFar(), endOffset)
 	}
-	c.Discard(blockSentinelLength)
+	if _, err := c.Discard(blockSentinelLength); err != nil {
+		return nil, c.readError(&element, errors.Wrap(err, "Failed to read block sentinel"))
+	}
 	//fmt.Println("With Sentinel", uint64(c.ReadSoFar()), "versus", endOffset)
 	return &element, nil
 }
 
-func tokenize(r io.Reader) (*Element, error) {
+// tokenize reads the elements of a binary FBX file and its header. It stops with the context's
+// error once the context is done, reports its progress to opts.Progress when it isn't nil, and
+// fails with a LimitError once the file goes over opts.Limits.
+func tokenize(ctx context.Context, r io.Reader, opts LoadOptions) (*Element, Header, error) {
 	countReader := NewCountReader(r)
 	r2 := bufio.NewReader(countReader)
-	cursor := &Cursor{r2, countReader}
+	progress := opts.Progress
+	cursor := &Cursor{Reader: r2, cr: countReader, ctx: ctx, progress: progress, limits: opts.Limits}
+	if progress != nil {
+		cursor.total = readerSize(r)
+	}
 	//fmt.Println("initial stats: ", r2.Buffered(), cursor.ReadSoFar())
 
 	ok := isBinary(cursor)
 	if !ok {
-		return nil, errors.New("Non-binary FBX")
+		return nil, Header{}, &ParseError{Err: errors.New("Non-binary FBX")}
 	}
 
 	var header Header
 	err := binary.Read(cursor, binary.LittleEndian, &header)
 	if err != nil {
-		return nil, err
+		return nil, header, cursor.readError(nil, errors.Wrap(err, "Failed to read header"))
 	}
 	//fmt.Println(header)
 
@@ -249,11 +375,14 @@ func tokenize(r io.Reader) (*Element, error) {
 		child, err := cursor.readElement(uint16(header.Version))
 		if err != nil {
 			//fmt.Println("Read element failure", err)
-			return nil, err
+			return nil, header, err
 		}
 
 		if child == nil {
-			return root, nil
+			if progress != nil {
+				progress(int64(cursor.ReadSoFar()), cursor.total)
+			}
+			return root, header, nil
 		}
 		root.Children = append(root.Children, child)
 	}
@@ -3,6 +3,7 @@ package ofbx
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"encoding/binary"
 	"testing"
 
@@ -172,14 +173,14 @@ FBXHeaderExtension:  {
 	Version: 7400
 }`)
 
-	_, err := tokenize(bytes.NewReader(asciiData))
+	_, _, err := tokenize(context.Background(), bytes.NewReader(asciiData), LoadOptions{})
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Non-binary FBX")
 }
 
 func TestTokenizeEmpty(t *testing.T) {
 	// Test with empty data
-	_, err := tokenize(bytes.NewReader([]byte{}))
+	_, _, err := tokenize(context.Background(), bytes.NewReader([]byte{}), LoadOptions{})
 	assert.Error(t, err)
 }
 
@@ -187,6 +188,6 @@ func TestTokenizeInvalidHeader(t *testing.T) {
 	// Test with invalid binary header
 	invalidData := []byte("Invalid FBX header\x00")
 
-	_, err := tokenize(bytes.NewReader(invalidData))
+	_, _, err := tokenize(context.Background(), bytes.NewReader(invalidData), LoadOptions{})
 	assert.Error(t, err)
 }
@@ -240,10 +240,15 @@ func (c *Cursor) readTextProperty() (*Property, error) {
 		return prop, err
 	}
 	//fmt.Println("r was", string(r))
-	return nil, errors.New("TODO")
+	return nil, errors.Errorf("Unexpected character %q in property", r)
 }
 
 func (c *Cursor) ReadTextElement() (*Element, error) {
+	c.depth++
+	defer func() { c.depth-- }()
+	if err := c.limits.checkDepth(c.depth); err != nil {
+		return nil, c.readError(nil, err)
+	}
 	//fmt.Println("Read text token start")
 	id, err := c.readTextToken()
 	if err != nil {
@@ -256,7 +261,7 @@ func (c *Cursor) ReadTextElement() (*Element, error) {
 	}
 	//fmt.Println("Read rune complete")
 	if r != ':' {
-		return nil, errors.New("Unexpected end of file")
+		return nil, c.readError(nil, errors.New("Unexpected end of file"))
 	}
 	//fmt.Println("Skip whitespaces start")
 	if err = c.skipWhitespaces(); err != nil {
@@ -281,7 +286,7 @@ func (c *Cursor) ReadTextElement() (*Element, error) {
 		}
 		prop, cerr := c.readTextProperty()
 		if cerr != nil {
-			return nil, cerr
+			return nil, c.readError(element, cerr)
 		}
 		by, err = c.Peek(1)
 		if err != io.EOF {
@@ -316,9 +321,12 @@ func (c *Cursor) ReadTextElement() (*Element, error) {
 				c.Discard(1)
 				break
 			}
+			if err := c.checkObjects(element); err != nil {
+				return nil, err
+			}
 			child, err := c.ReadTextElement()
 			if err != nil {
-				return nil, err
+				return nil, elementError(element, err)
 			}
 			c.skipWhitespaces()
 
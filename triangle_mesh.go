@@ -0,0 +1,150 @@
+package ofbx
+
+import (
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+)
+
+// TriangleMeshOptions controls what BuildTriangleMesh puts into its vertices
+type TriangleMeshOptions struct {
+	// NoNormals, NoTangents, NoUVs and NoColors leave the matching attribute out of the mesh,
+	// which also lets more polygon corners weld into the same vertex
+	NoNormals, NoTangents, NoUVs, NoColors bool
+	// NoWeld emits one vertex per triangle corner instead of merging identical corners
+	NoWeld bool
+	// Triangulation selects how polygons are split into triangles
+	Triangulation TriangulationMethod
+}
+
+// TriangleMesh is an indexed triangle list ready to be uploaded to a renderer.
+// Every attribute slice is either empty or holds one value per vertex.
+type TriangleMesh struct {
+	Positions []floatgeom.Point3
+	Normals   []floatgeom.Point3
+	// Tangents hold the bitangent sign in W, 1 when the geometry doesn't say
+	Tangents []floatgeom.Point4
+	UVs      [MaxUvs][]floatgeom.Point2
+	Colors   []floatgeom.Point4
+	// Indices holds three vertex indices per triangle
+	Indices []uint32
+	// Materials holds the material slot of each triangle
+	Materials []int
+	// ControlPoints maps each vertex back to its index in Geometry.Vertices
+	ControlPoints []int
+}
+
+// TriangleCount returns the number of triangles in the mesh
+func (tm *TriangleMesh) TriangleCount() int {
+	return len(tm.Indices) / 3
+}
+
+type meshVertexKey struct {
+	controlPoint int
+	normal       floatgeom.Point3
+	tangent      floatgeom.Point4
+	uvs          [MaxUvs]floatgeom.Point2
+	color        floatgeom.Point4
+	smoothing    int
+}
+
+// BuildTriangleMesh triangulates the geometry and welds polygon corners that share a control point
+// and every selected attribute into unique vertices. Triangles take the material slot of their
+// polygon, or slot 0 when the geometry has no material layer. Polygons flagged in Holes are cut
+// out of the polygon before them rather than emitted.
+func (g *Geometry) BuildTriangleMesh(opts TriangleMeshOptions) *TriangleMesh {
+	pvCount := 0
+	for _, face := range g.Faces {
+		pvCount += len(face)
+	}
+	hasAttr := func(n int) bool {
+		return n != 0 && n >= pvCount
+	}
+	useNormals := !opts.NoNormals && hasAttr(len(g.Normals))
+	useTangents := !opts.NoTangents && hasAttr(len(g.Tangents))
+	useColors := !opts.NoColors && hasAttr(len(g.Colors))
+	var useUVs [MaxUvs]bool
+	for i := range g.UVs {
+		useUVs[i] = !opts.NoUVs && hasAttr(len(g.UVs[i]))
+	}
+
+	// Corners on either side of a hard edge or smoothing group boundary are never welded
+	var smoothing []int
+	if useNormals {
+		smoothing = g.smoothingClasses()
+	}
+
+	tm := &TriangleMesh{}
+	welded := make(map[meshVertexKey]uint32)
+	vertexOf := func(cp, pv int) uint32 {
+		key := meshVertexKey{controlPoint: cp}
+		if useNormals {
+			key.normal = g.Normals[pv]
+		}
+		if smoothing != nil {
+			key.smoothing = smoothing[pv]
+		}
+		if useTangents {
+			t := g.Tangents[pv]
+			key.tangent = floatgeom.Point4{t[0], t[1], t[2], 1}
+			if pv < len(g.BitangentSigns) {
+				key.tangent[3] = g.BitangentSigns[pv]
+			}
+		}
+		if useColors {
+			key.color = g.Colors[pv]
+		}
+		for i := range useUVs {
+			if useUVs[i] {
+				key.uvs[i] = g.UVs[i][pv]
+			}
+		}
+		if idx, ok := welded[key]; ok && !opts.NoWeld {
+			return idx
+		}
+		idx := uint32(len(tm.Positions))
+		if !opts.NoWeld {
+			welded[key] = idx
+		}
+		tm.Positions = append(tm.Positions, g.Vertices[cp])
+		tm.ControlPoints = append(tm.ControlPoints, cp)
+		if useNormals {
+			tm.Normals = append(tm.Normals, key.normal)
+		}
+		if useTangents {
+			tm.Tangents = append(tm.Tangents, key.tangent)
+		}
+		if useColors {
+			tm.Colors = append(tm.Colors, key.color)
+		}
+		for i := range useUVs {
+			if useUVs[i] {
+				tm.UVs[i] = append(tm.UVs[i], key.uvs[i])
+			}
+		}
+		return idx
+	}
+
+	corners := g.cornerControlPoints()
+	for _, tri := range g.triangulateFaces(opts.Triangulation) {
+		for _, pv := range tri.corners {
+			tm.Indices = append(tm.Indices, vertexOf(corners[pv], pv))
+		}
+		material := 0
+		if len(g.polygonMaterials) != 0 {
+			material = -1
+			if tri.polygon < len(g.polygonMaterials) {
+				material = g.polygonMaterials[tri.polygon]
+			}
+		}
+		tm.Materials = append(tm.Materials, material)
+	}
+	return tm
+}
+
+// fanTriangles splits a polygon with n corners into triangles sharing its first corner
+func fanTriangles(n int) [][3]int {
+	tris := make([][3]int, 0, n-2)
+	for i := 2; i < n; i++ {
+		tris = append(tris, [3]int{0, i - 1, i})
+	}
+	return tris
+}
@@ -0,0 +1,64 @@
+package ofbx
+
+import (
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTriangleMeshCube(t *testing.T) {
+	scene := loadTestScene(t, "testdata/cube.fbx")
+	geom := scene.Meshes[0].Geometry
+
+	tm := geom.BuildTriangleMesh(TriangleMeshOptions{})
+	assert.Equal(t, 12, tm.TriangleCount())
+	// Corners only weld when their control point and normal match exactly
+	distinct := map[meshVertexKey]bool{}
+	pv := 0
+	for _, face := range geom.Faces {
+		for _, cp := range face {
+			distinct[meshVertexKey{controlPoint: cp, normal: geom.Normals[pv]}] = true
+			pv++
+		}
+	}
+	assert.Len(t, tm.Positions, len(distinct))
+	assert.Len(t, tm.Normals, len(distinct))
+	assert.Len(t, tm.ControlPoints, len(distinct))
+	assert.Empty(t, tm.Tangents)
+	assert.Len(t, tm.Materials, 12)
+	for i, cp := range tm.ControlPoints {
+		assert.Equal(t, geom.Vertices[cp], tm.Positions[i])
+	}
+	for _, idx := range tm.Indices {
+		assert.True(t, int(idx) < len(tm.Positions))
+	}
+
+	flat := geom.BuildTriangleMesh(TriangleMeshOptions{NoNormals: true})
+	assert.Len(t, flat.Positions, 8)
+	assert.Empty(t, flat.Normals)
+
+	unwelded := geom.BuildTriangleMesh(TriangleMeshOptions{NoWeld: true})
+	assert.Len(t, unwelded.Positions, 36)
+}
+
+func TestBuildTriangleMeshAttributes(t *testing.T) {
+	geom := NewGeometry(&Scene{}, &Element{ID: NewDataView("Geometry")})
+	geom.Vertices = []floatgeom.Point3{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}, {2, 0, 0}}
+	geom.Faces = [][]int{{0, 1, 2, 3}, {1, 4, 2}, {0, 1}}
+	geom.UVs[1] = []floatgeom.Point2{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {1, 0}, {2, 0}, {1, 1}, {0, 0}, {1, 0}}
+	geom.polygonMaterials = []int{2, 5}
+
+	tm := geom.BuildTriangleMesh(TriangleMeshOptions{})
+	assert.Equal(t, 3, tm.TriangleCount())
+	assert.Equal(t, []uint32{0, 1, 2, 0, 2, 3, 1, 4, 2}, tm.Indices)
+	assert.Equal(t, []int{2, 2, 5}, tm.Materials)
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, tm.ControlPoints)
+	assert.Len(t, tm.UVs[1], 5)
+	assert.Empty(t, tm.UVs[0])
+}
+
+func TestFanTriangles(t *testing.T) {
+	assert.Equal(t, [][3]int{{0, 1, 2}, {0, 2, 3}, {0, 3, 4}}, fanTriangles(5))
+	assert.Empty(t, fanTriangles(2))
+}
@@ -0,0 +1,370 @@
+package ofbx
+
+import (
+	"math"
+	"sort"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+)
+
+// TriangulationMethod selects how polygons with more than three corners are split into triangles
+type TriangulationMethod int
+
+// TriangulationMethod options
+const (
+	// TriangulateEarClipping handles concave and non-planar polygons, colinear corners and holes.
+	// Polygons with more than maxEarClippingCorners corners, holes included, are split as a fan.
+	TriangulateEarClipping TriangulationMethod = iota
+	// TriangulateFan connects every corner to the first one. It is fast but only correct for convex
+	// polygons, and it ignores holes.
+	TriangulateFan TriangulationMethod = iota
+)
+
+// maxEarClippingCorners bounds the quadratic work of ear clipping a single polygon
+const maxEarClippingCorners = 1 << 10
+
+// polygonTriangle is a triangle of a polygon, with corners given as polygon vertex indices
+type polygonTriangle struct {
+	polygon int
+	corners [3]int
+}
+
+// cornerControlPoints returns the control point of every polygon vertex
+func (g *Geometry) cornerControlPoints() []int {
+	corners := make([]int, 0)
+	for _, face := range g.Faces {
+		corners = append(corners, face...)
+	}
+	return corners
+}
+
+// triangulateFaces splits every polygon into triangles, cutting out the polygons flagged in Holes.
+// Polygons with fewer than three corners or with an invalid control point are skipped.
+func (g *Geometry) triangulateFaces(method TriangulationMethod) []polygonTriangle {
+	starts := make([]int, len(g.Faces))
+	start := 0
+	for poly, face := range g.Faces {
+		starts[poly] = start
+		start += len(face)
+	}
+	isHole := func(poly int) bool {
+		return poly < len(g.Holes) && g.Holes[poly]
+	}
+	validFace := func(face []int) bool {
+		if len(face) < 3 {
+			return false
+		}
+		for _, cp := range face {
+			if cp < 0 || cp >= len(g.Vertices) {
+				return false
+			}
+		}
+		return true
+	}
+
+	tris := make([]polygonTriangle, 0)
+	for poly, face := range g.Faces {
+		if isHole(poly) || !validFace(face) {
+			continue
+		}
+		// Gather the polygon and the holes that follow it into one list of corners
+		var points []floatgeom.Point3
+		var pvs []int
+		ringOf := func(poly int) []int {
+			ring := make([]int, len(g.Faces[poly]))
+			for i, cp := range g.Faces[poly] {
+				ring[i] = len(points)
+				points = append(points, g.Vertices[cp])
+				pvs = append(pvs, starts[poly]+i)
+			}
+			return ring
+		}
+		outer := ringOf(poly)
+		if method == TriangulateFan {
+			for _, tri := range fanRing(outer) {
+				tris = append(tris, polygonTriangle{poly, [3]int{pvs[tri[0]], pvs[tri[1]], pvs[tri[2]]}})
+			}
+			continue
+		}
+		var holes [][]int
+		for next := poly + 1; next < len(g.Faces) && isHole(next); next++ {
+			if validFace(g.Faces[next]) {
+				holes = append(holes, ringOf(next))
+			}
+		}
+		for _, tri := range triangulatePolygon(points, outer, holes) {
+			tris = append(tris, polygonTriangle{poly, [3]int{pvs[tri[0]], pvs[tri[1]], pvs[tri[2]]}})
+		}
+	}
+	return tris
+}
+
+// polygonNormal returns the best-fit plane normal of a ring of points using Newell's method.
+// The normal is not normalized, its length is twice the projected area.
+func polygonNormal(points []floatgeom.Point3, ring []int) floatgeom.Point3 {
+	var n floatgeom.Point3
+	for i, cur := range ring {
+		a, b := points[cur], points[ring[(i+1)%len(ring)]]
+		n[0] += (a[1] - b[1]) * (a[2] + b[2])
+		n[1] += (a[2] - b[2]) * (a[0] + b[0])
+		n[2] += (a[0] - b[0]) * (a[1] + b[1])
+	}
+	return n
+}
+
+// planeBasis returns two unit vectors spanning the plane with normal n, oriented so that u x v = n
+func planeBasis(n floatgeom.Point3) (u, v floatgeom.Point3) {
+	n = n.Normalize()
+	// Start from the axis least aligned with the normal
+	axis := floatgeom.Point3{1, 0, 0}
+	if math.Abs(n[1]) < math.Abs(n[0]) && math.Abs(n[1]) <= math.Abs(n[2]) {
+		axis = floatgeom.Point3{0, 1, 0}
+	} else if math.Abs(n[2]) < math.Abs(n[0]) {
+		axis = floatgeom.Point3{0, 0, 1}
+	}
+	u = axis.Cross(n).Normalize()
+	v = n.Cross(u)
+	return u, v
+}
+
+func cross2(o, a, b floatgeom.Point2) float64 {
+	return (a[0]-o[0])*(b[1]-o[1]) - (a[1]-o[1])*(b[0]-o[0])
+}
+
+func ringArea2(pts []floatgeom.Point2, ring []int) float64 {
+	area := 0.0
+	for i, cur := range ring {
+		next := ring[(i+1)%len(ring)]
+		area += pts[cur][0]*pts[next][1] - pts[next][0]*pts[cur][1]
+	}
+	return area
+}
+
+// triangulatePolygon splits a polygon, given as a ring of indices into points, into triangles.
+// Each hole ring is cut out of the polygon. The returned triangles index into points and keep
+// the winding of the outer ring. A polygon with n corners and no holes always yields n-2
+// triangles; degenerate corners produce zero-area triangles rather than being dropped.
+// Polygons with more than maxEarClippingCorners corners are split as a fan, without their holes.
+func triangulatePolygon(points []floatgeom.Point3, outer []int, holes [][]int) [][3]int {
+	if len(outer) < 3 {
+		return nil
+	}
+	if len(outer) == 3 && len(holes) == 0 {
+		return [][3]int{{outer[0], outer[1], outer[2]}}
+	}
+	corners := len(outer)
+	for _, hole := range holes {
+		corners += len(hole)
+	}
+	if corners > maxEarClippingCorners {
+		return fanRing(outer)
+	}
+	normal := polygonNormal(points, outer)
+	if normal.Magnitude() == 0 {
+		return fanRing(outer)
+	}
+	u, v := planeBasis(normal)
+	pts := make([]floatgeom.Point2, len(points))
+	for i, p := range points {
+		pts[i] = floatgeom.Point2{p.Dot(u), p.Dot(v)}
+	}
+
+	ring := append([]int{}, outer...)
+	if len(holes) != 0 {
+		ring = bridgeHoles(pts, ring, holes)
+	}
+	return clipEars(pts, ring)
+}
+
+// sequence returns the indices 0 to n-1
+func sequence(n int) []int {
+	seq := make([]int, n)
+	for i := range seq {
+		seq[i] = i
+	}
+	return seq
+}
+
+func fanRing(ring []int) [][3]int {
+	tris := make([][3]int, 0, len(ring))
+	for _, tri := range fanTriangles(len(ring)) {
+		tris = append(tris, [3]int{ring[tri[0]], ring[tri[1]], ring[tri[2]]})
+	}
+	return tris
+}
+
+// clipEars triangulates a counter clockwise ring by repeatedly cutting off ears
+func clipEars(pts []floatgeom.Point2, ring []int) [][3]int {
+	// Scale the degeneracy threshold to the polygon so it works at any unit scale
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, i := range ring {
+		minX, maxX = math.Min(minX, pts[i][0]), math.Max(maxX, pts[i][0])
+		minY, maxY = math.Min(minY, pts[i][1]), math.Max(maxY, pts[i][1])
+	}
+	eps := 1e-12 * math.Max(maxX-minX, maxY-minY) * math.Max(maxX-minX, maxY-minY)
+
+	// The corners left are linked through prev and next, by position in ring
+	n := len(ring)
+	prev, next := make([]int, n), make([]int, n)
+	for i := range ring {
+		prev[i], next[i] = (i+n-1)%n, (i+1)%n
+	}
+	removed := make([]bool, n)
+	cornerArea := func(i int) float64 {
+		return cross2(pts[ring[prev[i]]], pts[ring[i]], pts[ring[next[i]]])
+	}
+	// Only reflex corners can be inside an ear, and cutting an ear never makes its neighbours reflex
+	var reflex []int
+	for i := range ring {
+		if cornerArea(i) <= 0 {
+			reflex = append(reflex, i)
+		}
+	}
+	entersEar := func(a, b, c int) bool {
+		inside := false
+		kept := reflex[:0]
+		for _, i := range reflex {
+			if removed[i] || cornerArea(i) > 0 {
+				continue
+			}
+			kept = append(kept, i)
+			p := ring[i]
+			if inside || p == ring[a] || p == ring[b] || p == ring[c] {
+				continue
+			}
+			pa, pb, pc, pp := pts[ring[a]], pts[ring[b]], pts[ring[c]], pts[p]
+			if pp == pa || pp == pb || pp == pc {
+				continue
+			}
+			inside = cross2(pa, pb, pp) >= 0 && cross2(pb, pc, pp) >= 0 && cross2(pc, pa, pp) >= 0
+		}
+		reflex = kept
+		return inside
+	}
+
+	tris := make([][3]int, 0, n)
+	// Starting at the second corner makes convex polygons come out as a fan. Every search goes on
+	// from the last ear, so a polygon takes at most quadratic time.
+	cur := 1
+	for left := n; left > 3; left-- {
+		ear := -1
+		flattest, flattestArea := cur, math.Inf(1)
+		for k, i := 0, cur; k < left && ear < 0; k, i = k+1, next[i] {
+			area := cornerArea(i)
+			if math.Abs(area) < flattestArea {
+				flattest, flattestArea = i, math.Abs(area)
+			}
+			if area > eps && !entersEar(prev[i], i, next[i]) {
+				ear = i
+			}
+		}
+		if ear < 0 {
+			// Only reflex, colinear or self-intersecting corners are left: cut the flattest one,
+			// which can turn its neighbours reflex
+			ear = flattest
+			reflex = append(reflex, prev[ear], next[ear])
+		}
+		a, c := prev[ear], next[ear]
+		tris = append(tris, [3]int{ring[a], ring[ear], ring[c]})
+		next[a], prev[c] = c, a
+		removed[ear] = true
+		cur = c
+	}
+	first := 0
+	for removed[first] {
+		first++
+	}
+	return append(tris, [3]int{ring[first], ring[next[first]], ring[next[next[first]]]})
+}
+
+// bridgeHoles merges hole rings into the outer ring by cutting a two-way bridge from the
+// rightmost corner of each hole to a visible corner of the ring
+func bridgeHoles(pts []floatgeom.Point2, ring []int, holes [][]int) []int {
+	if ringArea2(pts, ring) < 0 {
+		ring = reversedRing(ring)
+	}
+	sorted := make([][]int, 0, len(holes))
+	for _, hole := range holes {
+		if len(hole) < 3 {
+			continue
+		}
+		// Holes run clockwise so the merged ring stays simple
+		if ringArea2(pts, hole) > 0 {
+			hole = reversedRing(hole)
+		}
+		sorted = append(sorted, hole)
+	}
+	rightmost := func(hole []int) int {
+		best := 0
+		for i, p := range hole {
+			if pts[p][0] > pts[hole[best]][0] {
+				best = i
+			}
+		}
+		return best
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return pts[sorted[i][rightmost(sorted[i])]][0] > pts[sorted[j][rightmost(sorted[j])]][0]
+	})
+
+	for h, hole := range sorted {
+		m := rightmost(hole)
+		bridge := -1
+		bestDist := math.Inf(1)
+		for i, p := range ring {
+			d := pts[p].Sub(pts[hole[m]])
+			dist := d[0]*d[0] + d[1]*d[1]
+			if dist >= bestDist {
+				continue
+			}
+			if segmentCrossesRings(pts, pts[p], pts[hole[m]], append([][]int{ring}, sorted[h:]...)) {
+				continue
+			}
+			bridge, bestDist = i, dist
+		}
+		if bridge < 0 {
+			// No clear line of sight, most likely a self-intersecting polygon; bridge to the closest corner
+			for i, p := range ring {
+				d := pts[p].Sub(pts[hole[m]])
+				if dist := d[0]*d[0] + d[1]*d[1]; dist < bestDist {
+					bridge, bestDist = i, dist
+				}
+			}
+		}
+		merged := make([]int, 0, len(ring)+len(hole)+2)
+		merged = append(merged, ring[:bridge+1]...)
+		for i := 0; i <= len(hole); i++ {
+			merged = append(merged, hole[(m+i)%len(hole)])
+		}
+		merged = append(merged, ring[bridge:]...)
+		ring = merged
+	}
+	return ring
+}
+
+func reversedRing(ring []int) []int {
+	out := make([]int, len(ring))
+	for i, p := range ring {
+		out[len(ring)-1-i] = p
+	}
+	return out
+}
+
+// segmentCrossesRings reports whether segment ab properly crosses an edge of any ring.
+// Edges touching a or b don't count.
+func segmentCrossesRings(pts []floatgeom.Point2, a, b floatgeom.Point2, rings [][]int) bool {
+	for _, ring := range rings {
+		for i, p := range ring {
+			c, d := pts[p], pts[ring[(i+1)%len(ring)]]
+			if c == a || c == b || d == a || d == b {
+				continue
+			}
+			d1, d2 := cross2(a, b, c), cross2(a, b, d)
+			d3, d4 := cross2(c, d, a), cross2(c, d, b)
+			if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package ofbx
+
+import (
+	"math"
+	"testing"
+
+	"github.com/oakmound/oak/v2/alg/floatgeom"
+	"github.com/stretchr/testify/assert"
+)
+
+func trianglesArea(points []floatgeom.Point3, tris [][3]int) float64 {
+	area := 0.0
+	for _, tri := range tris {
+		a, b, c := points[tri[0]], points[tri[1]], points[tri[2]]
+		area += b.Sub(a).Cross(c.Sub(a)).Magnitude() / 2
+	}
+	return area
+}
+
+func TestTriangulateConcave(t *testing.T) {
+	// An L shape, whose fan from the first corner would overlap itself
+	points := []floatgeom.Point3{{2, 1, 0}, {1, 1, 0}, {1, 2, 0}, {0, 2, 0}, {0, 0, 0}, {2, 0, 0}}
+	tris := triangulatePolygon(points, sequence(len(points)), nil)
+	assert.Len(t, tris, 4)
+	assert.InDelta(t, 3.0, trianglesArea(points, tris), 1e-9)
+	assert.Greater(t, trianglesArea(points, fanRing(sequence(len(points)))), 3.0)
+
+	// Every triangle keeps the winding of the polygon
+	normal := polygonNormal(points, sequence(len(points)))
+	for _, tri := range tris {
+		a, b, c := points[tri[0]], points[tri[1]], points[tri[2]]
+		assert.Greater(t, b.Sub(a).Cross(c.Sub(a)).Dot(normal), 0.0)
+	}
+}
+
+func TestTriangulateColinear(t *testing.T) {
+	points := []floatgeom.Point3{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {2, 1, 0}, {1, 1, 0}, {0, 1, 0}}
+	tris := triangulatePolygon(points, sequence(len(points)), nil)
+	assert.Len(t, tris, 4)
+	assert.InDelta(t, 2.0, trianglesArea(points, tris), 1e-9)
+
+	// Fully degenerate polygons still produce n-2 triangles
+	line := []floatgeom.Point3{{0, 0, 0}, {1, 0, 0}, {2, 0, 0}, {3, 0, 0}}
+	assert.Len(t, triangulatePolygon(line, sequence(len(line)), nil), 2)
+	same := []floatgeom.Point3{{1, 1, 1}, {1, 1, 1}, {1, 1, 1}, {1, 1, 1}, {1, 1, 1}}
+	assert.Len(t, triangulatePolygon(same, sequence(len(same)), nil), 3)
+}
+
+func TestTriangulateNonPlanar(t *testing.T) {
+	// A concave quad bent out of the XY plane and tilted away from every axis
+	points := []floatgeom.Point3{{0, 0, 0}, {4, 0, 0.1}, {1, 1, -0.1}, {0, 4, 0.1}}
+	tilt := EulerXYZ.rotationMatrix(floatgeom.Point3{30, 60, 10})
+	for i, p := range points {
+		points[i] = tilt.MulPosition(p)
+	}
+	tris := triangulatePolygon(points, sequence(len(points)), nil)
+	assert.Len(t, tris, 2)
+	for _, tri := range tris {
+		// The reflex corner must be shared by both triangles
+		assert.Contains(t, tri[:], 2)
+	}
+}
+
+// starPoints returns n corners alternating between radius 1 and 0.5, half of them reflex
+func starPoints(n int) []floatgeom.Point3 {
+	points := make([]floatgeom.Point3, n)
+	for i := range points {
+		a, r := 2*math.Pi*float64(i)/float64(n), 1.0
+		if i%2 == 1 {
+			r = 0.5
+		}
+		points[i] = floatgeom.Point3{r * math.Cos(a), r * math.Sin(a), 0}
+	}
+	return points
+}
+
+func TestTriangulateLargePolygons(t *testing.T) {
+	star := starPoints(maxEarClippingCorners)
+	tris := triangulatePolygon(star, sequence(len(star)), nil)
+	assert.Len(t, tris, len(star)-2)
+	// The area of n kites between the radii
+	assert.InDelta(t, float64(len(star))/2*0.5*math.Sin(2*math.Pi/float64(len(star))), trianglesArea(star, tris), 1e-9)
+
+	// Larger polygons aren't worth the quadratic time of ear clipping
+	star = starPoints(maxEarClippingCorners + 2)
+	assert.Equal(t, fanRing(sequence(len(star))), triangulatePolygon(star, sequence(len(star)), nil))
+}
+
+func TestTriangulateHoles(t *testing.T) {
+	points := []floatgeom.Point3{
+		{0, 0, 0}, {4, 0, 0}, {4, 4, 0}, {0, 4, 0},
+		{1, 1, 0}, {2, 1, 0}, {2, 2, 0}, {1, 2, 0},
+		{3, 3, 0}, {3.5, 3, 0}, {3.5, 3.5, 0},
+	}
+	holes := [][]int{{4, 5, 6, 7}, {8, 9, 10}}
+	tris := triangulatePolygon(points, []int{0, 1, 2, 3}, holes)
+	// Each bridge duplicates two corners, and a ring of n corners yields n-2 triangles
+	assert.Len(t, tris, 4+4+3+2*2-2)
+	assert.InDelta(t, 16-1-0.125, trianglesArea(points, tris), 1e-9)
+}
+
+func TestBuildTriangleMeshHoles(t *testing.T) {
+	geom := NewGeometry(&Scene{}, &Element{ID: NewDataView("Geometry")})
+	geom.Vertices = []floatgeom.Point3{
+		{0, 0, 0}, {3, 0, 0}, {3, 3, 0}, {0, 3, 0},
+		{1, 1, 0}, {2, 1, 0}, {2, 2, 0}, {1, 2, 0},
+	}
+	geom.Faces = [][]int{{0, 1, 2, 3}, {4, 5, 6, 7}}
+	geom.Holes = []bool{false, true}
+	geom.polygonMaterials = []int{1, 0}
+
+	tm := geom.BuildTriangleMesh(TriangleMeshOptions{})
+	assert.Equal(t, 8, tm.TriangleCount())
+	for _, m := range tm.Materials {
+		assert.Equal(t, 1, m)
+	}
+	area := 0.0
+	for i := 0; i < len(tm.Indices); i += 3 {
+		area += trianglesArea(tm.Positions, [][3]int{{int(tm.Indices[i]), int(tm.Indices[i+1]), int(tm.Indices[i+2])}})
+	}
+	assert.InDelta(t, 8.0, area, 1e-9)
+
+	fan := geom.BuildTriangleMesh(TriangleMeshOptions{Triangulation: TriangulateFan})
+	assert.Equal(t, 2, fan.TriangleCount())
+	assert.Equal(t, []uint32{0, 1, 2, 0, 2, 3}, fan.Indices)
+}
+
+func TestPlaneBasis(t *testing.T) {
+	for _, n := range []floatgeom.Point3{{0, 0, 1}, {0, -2, 0}, {1, 1, 1}, {-3, 0.5, 0.1}} {
+		u, v := planeBasis(n)
+		assert.InDelta(t, 1.0, u.Magnitude(), 1e-9)
+		assert.InDelta(t, 0.0, u.Dot(v), 1e-9)
+		assert.InDelta(t, 0.0, math.Abs(u.Dot(n)), 1e-9)
+		assertPointsInDelta(t, n.Normalize(), u.Cross(v))
+	}
+}
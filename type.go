@@ -20,6 +20,8 @@ const (
 	ANIMATION_CURVE      Type = iota
 	ANIMATION_CURVE_NODE Type = iota
 	NOTYPE               Type = iota
+	LAYERED_TEXTURE      Type = iota
+	VIDEO                Type = iota
 )
 
 var (
@@ -38,6 +40,8 @@ var (
 		ANIMATION_LAYER:      "animation layer",
 		ANIMATION_CURVE:      "animation curve",
 		ANIMATION_CURVE_NODE: "animation curve node",
+		LAYERED_TEXTURE:      "layered texture",
+		VIDEO:                "video",
 	}
 )
 
@@ -0,0 +1,97 @@
+package ofbx
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Video is an image or movie file used by textures, whose bytes are embedded in Content
+// when the file was exported with embedded media
+type Video struct {
+	Object
+	Filename, RelativeFilename string
+	Content                    []byte
+}
+
+// NewVideo creates a stub Video
+func NewVideo(scene *Scene, element *Element) *Video {
+	return &Video{
+		Object: *NewObject(scene, element),
+	}
+}
+
+// Type returns VIDEO
+func (v *Video) Type() Type {
+	return VIDEO
+}
+
+// BaseName returns the file name of the video without its directory, whichever
+// path separator the exporting platform used. It is empty when neither file name ends in
+// one that is safe to create inside a directory.
+func (v *Video) BaseName() string {
+	for _, name := range []string{v.RelativeFilename, v.Filename} {
+		name = strings.ReplaceAll(name, "\\", "/")
+		base := path.Base(name)
+		// Drop a Windows drive, as in "C:name", which is relative to that drive's directory
+		if i := strings.LastIndex(base, ":"); i >= 0 {
+			base = base[i+1:]
+		}
+		if isLocalName(base) {
+			return base
+		}
+	}
+	return ""
+}
+
+// isLocalName reports whether name is a plain file name that stays inside the directory it is
+// joined to
+func isLocalName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\:\x00")
+}
+
+func (v *Video) String() string {
+	return v.stringPrefix("")
+}
+
+func (v *Video) stringPrefix(prefix string) string {
+	return prefix + "Video: " + v.Object.String() + fmt.Sprintf(", filename: %s, embedded bytes: %d", v.Filename, len(v.Content))
+}
+
+// ExtractMedia writes the content of every video with embedded media into dir and returns the paths
+// written. Files are named after the video's file name, with a numeric suffix when several videos
+// share one.
+func (s *Scene) ExtractMedia(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "Failed to create media directory")
+	}
+	written := make([]string, 0)
+	used := make(map[string]bool)
+	for _, v := range s.Videos {
+		if len(v.Content) == 0 {
+			continue
+		}
+		name := v.BaseName()
+		if !isLocalName(name) {
+			name = fmt.Sprintf("video%d", v.ID())
+		}
+		ext := path.Ext(name)
+		stem := strings.TrimSuffix(name, ext)
+		for i := 1; used[strings.ToLower(name)]; i++ {
+			name = fmt.Sprintf("%s_%d%s", stem, i, ext)
+		}
+		used[strings.ToLower(name)] = true
+
+		out := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(out, v.Content, 0644); err != nil {
+			return written, errors.Wrap(err, "Failed to write media")
+		}
+		written = append(written, out)
+	}
+	return written, nil
+}
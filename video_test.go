@@ -0,0 +1,102 @@
+package ofbx
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVideoContent(t *testing.T) {
+	objects := []*Element{
+		newTextureElement(1,
+			newTestElement("FileName", []*Property{newStringProperty(`C:\assets\wood.png`)}),
+		),
+		newVideoElement(2, `C:\assets\wood.png`, "\x89PNG"),
+		newTextureElement(3,
+			newTestElement("FileName", []*Property{newStringProperty("stone.jpg")}),
+		),
+	}
+	scene := loadTestElements(t, objects, newConnection("OO", 2, 1))
+
+	require.Len(t, scene.Videos, 1)
+	video := scene.Videos[0]
+	assert.Equal(t, "wood.png", video.BaseName())
+
+	tex := scene.ObjectMap[1].(*Texture)
+	assert.Same(t, video, tex.Video())
+	name, content := tex.Media()
+	assert.Equal(t, `C:\assets\wood.png`, name)
+	assert.Equal(t, []byte("\x89PNG"), content)
+
+	name, content = scene.ObjectMap[3].(*Texture).Media()
+	assert.Equal(t, "stone.jpg", name)
+	assert.Nil(t, content)
+}
+
+func TestExtractMedia(t *testing.T) {
+	scene := loadTestElements(t, []*Element{
+		newVideoElement(1, `C:\a\wood.png`, "first"),
+		newVideoElement(2, "/b/Wood.png", "second"),
+		newVideoElement(3, "linked.png", ""),
+	})
+	dir, err := ioutil.TempDir("", "ofbx")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	paths, err := scene.ExtractMedia(filepath.Join(dir, "media"))
+	require.NoError(t, err)
+	require.Equal(t, []string{filepath.Join(dir, "media", "wood.png"), filepath.Join(dir, "media", "Wood_1.png")}, paths)
+	for i, want := range []string{"first", "second"} {
+		got, err := ioutil.ReadFile(paths[i])
+		require.NoError(t, err)
+		assert.Equal(t, want, string(got))
+	}
+}
+
+func TestExtractMediaUnsafeNames(t *testing.T) {
+	videos := []*Element{
+		newVideoElement(1, `C:\media\evil`, "first"),
+		newVideoElement(2, `..`, "second"),
+		newVideoElement(3, `C:x`, "third"),
+		newVideoElement(4, `a/.`, "fourth"),
+	}
+	videos[0].Children = append(videos[0].Children, newTestElement("RelativeFilename", []*Property{newStringProperty(`..\..\evil`)}))
+	videos[1].Children = append(videos[1].Children, newTestElement("RelativeFilename", []*Property{newStringProperty(`..\`)}))
+	scene := loadTestElements(t, videos)
+	dir, err := ioutil.TempDir("", "ofbx")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	media := filepath.Join(dir, "media")
+	paths, err := scene.ExtractMedia(media)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(media, "evil"),
+		filepath.Join(media, "video2"),
+		filepath.Join(media, "x"),
+		filepath.Join(media, "video4"),
+	}, paths)
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestVideosFromFile(t *testing.T) {
+	scene := loadTestScene(t, "testdata/FBXcs2.fbx")
+	require.NotEmpty(t, scene.Videos)
+	for _, v := range scene.Videos {
+		assert.NotEmpty(t, v.Filename)
+		assert.Empty(t, v.Content)
+	}
+	linked := 0
+	for _, obj := range scene.ObjectMap {
+		if tex, ok := obj.(*Texture); ok && tex.Video() != nil {
+			linked++
+		}
+	}
+	assert.NotZero(t, linked)
+}
@@ -0,0 +1,80 @@
+package ofbx
+
+import "fmt"
+
+// WarningCode tells what kind of problem a Warning reports
+type WarningCode int
+
+// WarningCode options
+const (
+	// WarningInvalidObject is an object that failed to parse and was left out
+	WarningInvalidObject WarningCode = iota
+	// WarningInvalidLayer is a layer element of a geometry that failed to parse and was left out
+	WarningInvalidLayer WarningCode = iota
+	// WarningInvalidConnection is a connection that failed to parse and was left out
+	WarningInvalidConnection WarningCode = iota
+	// WarningInvalidTake is a take that failed to parse and was left out
+	WarningInvalidTake WarningCode = iota
+	// WarningDuplicateGeometry is a second geometry on a mesh, which was ignored
+	WarningDuplicateGeometry WarningCode = iota
+	// WarningDuplicateNodeAttribute is a second node attribute on a node, which was ignored
+	WarningDuplicateNodeAttribute WarningCode = iota
+	// WarningMultipleSkins is a cluster assigned to more than one skin, which stays with the first
+	WarningMultipleSkins WarningCode = iota
+	// WarningDuplicateLink is a second node linked to a cluster, which was ignored
+	WarningDuplicateLink WarningCode = iota
+	// WarningTooManyCurves is a fourth curve on a curve node, which was ignored
+	WarningTooManyCurves WarningCode = iota
+	// WarningPostProcess is an object that failed to post process and may be incomplete
+	WarningPostProcess WarningCode = iota
+)
+
+var warningCodeNames = []string{
+	"invalid object",
+	"invalid layer",
+	"invalid connection",
+	"invalid take",
+	"duplicate geometry",
+	"duplicate node attribute",
+	"multiple skins",
+	"duplicate link",
+	"too many curves",
+	"post process",
+}
+
+func (c WarningCode) String() string {
+	if c < 0 || int(c) >= len(warningCodeNames) {
+		return "unknown"
+	}
+	return warningCodeNames[c]
+}
+
+// Warning is a problem that lenient loading skipped or repaired instead of failing
+type Warning struct {
+	Code WarningCode
+	// ObjectID is the ID of the object the problem is about, 0 when it isn't about an object
+	ObjectID uint64
+	Message  string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%v (object %d): %s", w.Code, w.ObjectID, w.Message)
+}
+
+// tolerate returns err when loading strictly. When loading leniently it records err as a warning
+// and returns nil, so that the caller skips or repairs what failed.
+func (s *Scene) tolerate(code WarningCode, id uint64, err error) error {
+	if s == nil || !s.options.Lenient || err == nil {
+		return err
+	}
+	s.Warnings = append(s.Warnings, Warning{Code: code, ObjectID: id, Message: err.Error()})
+	return nil
+}
+
+// elementObjectID returns the ID in the first property of an object's element, or 0
+func elementObjectID(elem *Element) uint64 {
+	if prop := elem.getProperty(0); isLong(prop) {
+		return prop.value.touint64()
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package ofbx
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBrokenScene has one of every problem lenient loading can skip
+func newBrokenScene() *Element {
+	return newTestRoot([]*Element{
+		newObjectElement("Model", 1, "Mesh"),
+		newTriangleGeometry(2),
+		newTriangleGeometry(3),
+		newObjectElement("Model", 4, "Mesh"),
+		newTriangleGeometry(5, newTestElement("LayerElementUV", []*Property{newIntegerProperty(0)},
+			newTestElement("MappingInformationType", []*Property{newStringProperty("Sideways")}),
+			newTestElement("UV", []*Property{newDoubleArrayProperty(0, 0)}),
+		)),
+		newObjectElement("Deformer", 6, "Cluster"),
+		newObjectElement("Deformer", 7, "Skin"),
+		newObjectElement("Deformer", 8, "Skin"),
+		newObjectElement("AnimationCurveNode", 9, ""),
+		newObjectElement("AnimationCurve", 10, ""),
+		newObjectElement("AnimationCurve", 11, ""),
+		newObjectElement("AnimationCurve", 12, ""),
+		newObjectElement("AnimationCurve", 13, ""),
+		newObjectElement("LayeredTexture", 15, "", newTestElement("BlendModes", []*Property{newDoubleArrayProperty(1)})),
+		newTestElement("Model", []*Property{newStringProperty("no id")}),
+	},
+		newConnection("OO", 2, 1),
+		newConnection("OO", 3, 1),
+		newConnection("OO", 5, 4),
+		newConnection("OO", 6, 7),
+		newConnection("OO", 6, 8),
+		newConnection("OO", 10, 9),
+		newConnection("OO", 11, 9),
+		newConnection("OO", 12, 9),
+		newConnection("OO", 13, 9),
+		newConnection("XX", 1, 0),
+	)
+}
+
+func TestLenientLoad(t *testing.T) {
+	_, err := newScene(context.Background(), newBrokenScene(), Header{Version: 7400}, LoadOptions{})
+	assert.Error(t, err)
+
+	scene, err := newScene(context.Background(), newBrokenScene(), Header{Version: 7400}, LoadOptions{Lenient: true})
+	require.NoError(t, err)
+
+	codes := map[WarningCode][]uint64{}
+	for _, w := range scene.Warnings {
+		assert.NotEmpty(t, w.Message, w.String())
+		codes[w.Code] = append(codes[w.Code], w.ObjectID)
+	}
+	assert.Equal(t, map[WarningCode][]uint64{
+		WarningInvalidConnection: {0},
+		WarningInvalidObject:     {15, 0},
+		WarningInvalidLayer:      {5},
+		WarningDuplicateGeometry: {1},
+		WarningMultipleSkins:     {8},
+		WarningTooManyCurves:     {9},
+		// The skin isn't on a geometry
+		WarningPostProcess: {6},
+	}, codes)
+
+	// The first of each duplicate is kept
+	mesh := scene.ObjectMap[1].(*Mesh)
+	assert.Same(t, scene.ObjectMap[2], mesh.Geometry)
+	geom := scene.ObjectMap[5].(*Geometry)
+	assert.Same(t, geom, scene.ObjectMap[4].(*Mesh).Geometry)
+	assert.Empty(t, geom.UVSets)
+	assert.Len(t, geom.Vertices, 3)
+	cluster := scene.ObjectMap[6].(*Cluster)
+	assert.Same(t, scene.ObjectMap[7], cluster.Skin)
+	assert.Empty(t, scene.ObjectMap[8].(*Skin).Clusters)
+	node := scene.ObjectMap[9].(*AnimationCurveNode)
+	assert.Same(t, scene.ObjectMap[12], node.Curves[2].Curve)
+	assert.Len(t, scene.Meshes, 2)
+}
+
+func TestWarningCodeString(t *testing.T) {
+	assert.Equal(t, "too many curves", WarningTooManyCurves.String())
+	assert.Equal(t, "unknown", WarningCode(-1).String())
+	assert.Equal(t, "duplicate geometry (object 1): second", Warning{Code: WarningDuplicateGeometry, ObjectID: 1, Message: "second"}.String())
+}